@@ -20,12 +20,16 @@ Examples:
 	RunE: runConfig,
 }
 
+var configURL string
+var configBackend string
 var configTopic string
 var configServer string
 var configPriority string
 var configToken string
 
 func init() {
+	configCmd.Flags().StringVar(&configURL, "url", "", "set destination URL, e.g. ntfy://host/topic")
+	configCmd.Flags().StringVar(&configBackend, "backend", "", "set notification backend")
 	configCmd.Flags().StringVar(&configTopic, "topic", "", "set ntfy topic")
 	configCmd.Flags().StringVar(&configServer, "server", "", "set ntfy server")
 	configCmd.Flags().StringVar(&configPriority, "priority", "", "set default priority")
@@ -36,6 +40,17 @@ func init() {
 func runConfig(cmd *cobra.Command, args []string) error {
 	changed := false
 
+	if configURL != "" {
+		if _, err := config.ParseURL(configURL); err != nil {
+			return err
+		}
+		cfg.URL = configURL
+		changed = true
+	}
+	if configBackend != "" {
+		cfg.Backend = configBackend
+		changed = true
+	}
 	if configTopic != "" {
 		cfg.Topic = configTopic
 		changed = true
@@ -63,13 +78,27 @@ func runConfig(cmd *cobra.Command, args []string) error {
 
 	// Always display current config
 	fmt.Println()
+	if cfg.URL != "" {
+		fmt.Printf("  url:      %s\n", maskToken(cfg.URL))
+	}
+	fmt.Printf("  backend:  %s\n", cfg.Backend)
 	fmt.Printf("  server:   %s\n", cfg.Server)
 	fmt.Printf("  topic:    %s\n", displayValue(cfg.Topic))
 	fmt.Printf("  priority: %s\n", cfg.Priority)
 	fmt.Printf("  token:    %s\n", maskToken(cfg.Token))
+	if cfg.User != "" {
+		fmt.Printf("  user:     %s\n", cfg.User)
+		fmt.Println("  password: ****")
+	}
+	for _, d := range cfg.NamedDestinations() {
+		if r, err := d.Resolve(); err == nil {
+			d = r
+		}
+		fmt.Printf("  destination %s: %s\n", d.Name, d.Backend)
+	}
 	fmt.Println()
 
-	if cfg.Topic == "" {
+	if cfg.Topic == "" && cfg.URL == "" && len(cfg.Destinations) == 0 {
 		fmt.Println("⚠️  No topic set. Run: tn config --topic <your-topic>")
 	}
 
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lee/term_notify/internal/config"
+)
+
+// runLoginCmd runs tn with args against a config file holding yaml and
+// returns the file's contents afterwards along with the command's error.
+func runLoginCmd(t *testing.T, yaml string, args ...string) (string, error) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("APPDATA", home)
+	path, err := config.ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := cfg
+	t.Cleanup(func() {
+		cfg = saved
+		loginToken, loginUser, loginPassword, flagPriority = "", "", "", ""
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+	})
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(io.Discard)
+	runErr := rootCmd.Execute()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data), runErr
+}
+
+func newAccountServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"username":"alice"}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLogin_KeepsConfiguredURL(t *testing.T) {
+	server := newAccountServer(t)
+	const yaml = "url: ntfys://tk_old@ntfy.example.com/builds\n"
+
+	data, err := runLoginCmd(t, yaml, "login", "--token", "tk_new", server.URL)
+	if err == nil || !strings.Contains(err.Error(), "destination URL") {
+		t.Errorf("login error = %v, want the configured URL reported", err)
+	}
+	if data != yaml {
+		t.Errorf("config file = %q, want it unchanged", data)
+	}
+}
+
+func TestLogin_SavesOnlyCredentials(t *testing.T) {
+	server := newAccountServer(t)
+	t.Setenv("TN_TOPIC", "from-env")
+
+	data, err := runLoginCmd(t, "topic: builds\npriority: high\n", "login", "--token", "tk_new", "--priority", "max", server.URL)
+	if err != nil {
+		t.Fatalf("login error = %v", err)
+	}
+	for _, want := range []string{"token: tk_new", "topic: builds", "priority: high", "server: " + server.URL} {
+		if !strings.Contains(data, want) {
+			t.Errorf("config file = %q, want it to contain %q", data, want)
+		}
+	}
+}
@@ -1,15 +1,17 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/lee/term_notify/internal/notifier"
 	"github.com/spf13/cobra"
 )
 
-var notifyTitle string
+var (
+	notifyTitle string
+	notifyDelay string
+)
 
 var notifyCmd = &cobra.Command{
 	Use:   "notify <message>",
@@ -20,17 +22,37 @@ Useful for chaining with other commands.
 Examples:
   tn notify "Build complete!"
   make build; tn notify "Build finished"
-  tn notify --title "Deploy" "Deployed to production"`,
+  tn notify --title "Deploy" "Deployed to production"
+  tn notify --click https://ci.example.com/job/42 "Build finished"
+  tn notify --action "view, Open logs, https://ci.example.com/job/42/log" "Build failed"
+  tn notify --delay 1h "Check the deploy"`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runNotify,
 }
 
 func init() {
 	notifyCmd.Flags().StringVar(&notifyTitle, "title", "", "notification title")
+	notifyCmd.Flags().StringVar(&notifyDelay, "delay", "", `deliver later: a duration or time, as for "tn remind"`)
+	addMessageFlags(notifyCmd)
 	rootCmd.AddCommand(notifyCmd)
 }
 
 func runNotify(cmd *cobra.Command, args []string) error {
+	actions, err := actionFlags()
+	if err != nil {
+		return err
+	}
+
+	var delay time.Duration
+	if notifyDelay != "" {
+		now := time.Now()
+		at, err := parseWhen(notifyDelay, now)
+		if err != nil {
+			return err
+		}
+		delay = at.Sub(now)
+	}
+
 	body := strings.Join(args, " ")
 	title := notifyTitle
 	if title == "" {
@@ -43,20 +65,13 @@ func runNotify(cmd *cobra.Command, args []string) error {
 	}
 
 	msg := &notifier.Message{
-		Server:   cfg.Server,
-		Topic:    cfg.Topic,
 		Title:    title,
 		Body:     body,
 		Priority: cfg.Priority,
 		Tags:     tags,
-		Token:    cfg.Token,
-	}
-
-	if err := notifier.Send(msg); err != nil {
-		fmt.Fprintf(os.Stderr, "tn: notification failed: %v\n", err)
-		return err
+		Delay:    delay,
 	}
+	applyMessageFlags(msg, actions)
 
-	fmt.Fprintf(os.Stderr, "tn: notification sent → %s/%s\n", cfg.Server, cfg.Topic)
-	return nil
+	return deliver(msg)
 }
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lee/term_notify/internal/config"
+	"github.com/lee/term_notify/internal/notifier"
+	"github.com/lee/term_notify/internal/outbox"
+	"github.com/spf13/cobra"
+)
+
+// outboxTimeout bounds each attempt to resend a queued notification before
+// a new one goes out, so an unreachable server does not hold up tn.
+const outboxTimeout = 10 * time.Second
+
+var outboxCmd = &cobra.Command{
+	Use:   "outbox",
+	Short: "List, resend or discard notifications that could not be sent",
+	Long: `When a notification cannot be delivered because the network or the
+server is down, tn keeps it in an outbox in the config directory. Queued
+notifications are resent the next time tn sends one, or with
+'tn outbox flush'.
+
+Examples:
+  tn outbox list    # show what is queued
+  tn outbox flush   # try to send everything now
+  tn outbox purge   # discard everything`,
+	Args: cobra.NoArgs,
+	RunE: runOutboxList,
+}
+
+var outboxListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show queued notifications",
+	Args:  cobra.NoArgs,
+	RunE:  runOutboxList,
+}
+
+var outboxFlushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "Try to send every queued notification now",
+	Args:  cobra.NoArgs,
+	RunE:  runOutboxFlush,
+}
+
+var outboxPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Discard every queued notification",
+	Args:  cobra.NoArgs,
+	RunE:  runOutboxPurge,
+}
+
+func init() {
+	outboxCmd.AddCommand(outboxListCmd, outboxFlushCmd, outboxPurgeCmd)
+	rootCmd.AddCommand(outboxCmd)
+}
+
+func runOutboxList(cmd *cobra.Command, args []string) error {
+	ob, err := outbox.Open()
+	if err != nil {
+		return err
+	}
+	entries, err := ob.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("The outbox is empty.")
+		return nil
+	}
+
+	fmt.Printf("%d queued in %s:\n\n", len(entries), ob.Dir())
+	for _, e := range entries {
+		d := e.Destination
+		fmt.Printf("  %s  %s (%s)  %s\n", e.Queued.Format("2006-01-02 15:04"), d.Name, d.Backend, displayValue(e.Message.Title))
+		reason, _, _ := strings.Cut(e.Error, "\n")
+		fmt.Printf("      %s, last error: %s\n", plural(e.Attempts, "attempt"), reason)
+	}
+	fmt.Println()
+	return nil
+}
+
+func runOutboxFlush(cmd *cobra.Command, args []string) error {
+	ob, err := outbox.Open()
+	if err != nil {
+		return err
+	}
+	sent, left, err := flushOutbox(ob, retryPolicy(), recorders(), true)
+	if err != nil {
+		return err
+	}
+	if sent == 0 && left == 0 {
+		fmt.Println("The outbox is empty.")
+		return nil
+	}
+	if left > 0 {
+		return fmt.Errorf("%d of %d queued notifications could not be sent", left, sent+left)
+	}
+	return nil
+}
+
+func runOutboxPurge(cmd *cobra.Command, args []string) error {
+	ob, err := outbox.Open()
+	if err != nil {
+		return err
+	}
+	n, err := ob.Purge()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Discarded %s.\n", plural(n, "queued notification"))
+	return nil
+}
+
+// plural formats a count of things, e.g. "1 attempt" or "3 attempts".
+func plural(n int, thing string) string {
+	if n == 1 {
+		return "1 " + thing
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// resendQueued tries once more to send what earlier runs left in the
+// outbox, so that it arrives before the notification about to be sent.
+// It stops at the first network failure, which the new notification would
+// most likely run into as well.
+func resendQueued() {
+	ob, err := outbox.Open()
+	if err != nil {
+		return
+	}
+	_, _, _ = flushOutbox(ob, notifier.RetryPolicy{Attempts: 1, Deadline: outboxTimeout}, recorders(), false)
+}
+
+// recorders returns the configured destinations that log deliveries, such
+// as an audit file, so that resent notifications are logged too.
+func recorders() []notifier.Destination {
+	var recs []notifier.Destination
+	for _, d := range destinations() {
+		d, err := d.Resolve()
+		if err != nil {
+			continue
+		}
+		n, err := newNotifier(d)
+		if err != nil {
+			continue
+		}
+		if _, ok := n.(notifier.Recorder); ok {
+			recs = append(recs, notifier.Destination{Name: d.Name, Priority: d.Priority, Notifier: n})
+		}
+	}
+	return recs
+}
+
+// flushOutbox resends the queued notifications, oldest first, removing
+// those that are delivered or rejected for good. Each resend is logged
+// with recs. With verbose set, network failures are reported and do not
+// stop the flush.
+func flushOutbox(ob *outbox.Outbox, policy notifier.RetryPolicy, recs []notifier.Destination, verbose bool) (sent, left int, err error) {
+	entries, err := ob.List()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i, e := range entries {
+		if !ob.Claim(e) {
+			continue
+		}
+
+		d := e.Destination
+		r := notifier.Result{Destination: d.Name, Backend: d.Backend}
+		n, err := newNotifier(d)
+		if err == nil {
+			dests := append([]notifier.Destination{{Name: d.Name, Priority: d.Priority, Notifier: n, Retry: policy}}, recs...)
+			results := notifier.Dispatch(context.Background(), dests, e.ResendMessage())
+			r = results[0]
+			for _, rr := range results[1:] {
+				if rr.Err != nil {
+					fmt.Fprintf(os.Stderr, "tn: notification failed → %s (%s): %v\n", rr.Destination, rr.Backend, rr.Err)
+				}
+			}
+		} else {
+			r.Err = err
+		}
+
+		switch {
+		case r.Err == nil:
+			if err := ob.Remove(e); err != nil {
+				return sent, len(entries) - sent, err
+			}
+			fmt.Fprintf(os.Stderr, "tn: queued notification sent → %s (%s), queued %s\n",
+				d.Name, describe(n), e.Queued.Format("2006-01-02 15:04"))
+			sent++
+
+		case !notifier.Retryable(r.Err):
+			// Trying again would only fail the same way.
+			if err := ob.Remove(e); err != nil {
+				return sent, len(entries) - sent, err
+			}
+			fmt.Fprintf(os.Stderr, "tn: queued notification dropped → %s (%s): %v\n", d.Name, d.Backend, r.Err)
+
+		default:
+			e.Attempts += max(r.Attempts, 1)
+			e.Error = r.Err.Error()
+			if err := ob.Release(e); err != nil {
+				return sent, len(entries) - sent, err
+			}
+			left++
+			if !verbose {
+				return sent, len(entries) - i - 1 + left, nil
+			}
+			fmt.Fprintf(os.Stderr, "tn: queued notification failed → %s (%s)%s: %v\n", d.Name, d.Backend, attempts(r), r.Err)
+		}
+	}
+	return sent, left, nil
+}
+
+// queue keeps msg in the outbox for a destination it could not reach.
+func queue(d config.Destination, msg *notifier.Message, r notifier.Result) {
+	ob, err := outbox.Open()
+	if err == nil {
+		_, err = ob.Add(d, msg, r.Attempts, r.Err)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tn: could not queue notification for %s: %v\n", d.Name, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "tn: notification queued → %s; it will be resent next time (see 'tn outbox')\n", d.Name)
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lee/term_notify/internal/config"
+	"github.com/lee/term_notify/internal/notifier"
+	"github.com/lee/term_notify/internal/outbox"
+)
+
+// webhookServer records the titles and priorities it receives, answering
+// with status when it is set and 200 otherwise.
+type webhookServer struct {
+	*httptest.Server
+	status atomic.Int32
+
+	mu         sync.Mutex
+	titles     []string
+	priorities []string
+}
+
+func newWebhookServer(t *testing.T) *webhookServer {
+	t.Helper()
+	s := &webhookServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status := int(s.status.Load()); status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		var body struct{ Title, Priority string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.mu.Lock()
+		s.titles = append(s.titles, body.Title)
+		s.priorities = append(s.priorities, body.Priority)
+		s.mu.Unlock()
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *webhookServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.titles...)
+}
+
+// useConfig points tn at a temporary config directory and sends to dests.
+func useConfig(t *testing.T, dests ...config.Destination) *outbox.Outbox {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("APPDATA", home)
+
+	saved := cfg
+	t.Cleanup(func() { cfg = saved })
+	cfg = config.DefaultConfig()
+	cfg.Retries = 0
+	cfg.Destinations = dests
+
+	ob, err := outbox.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ob
+}
+
+func TestDeliver_QueuesAndResends(t *testing.T) {
+	server := newWebhookServer(t)
+	logPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	ob := useConfig(t,
+		config.Destination{Name: "hook", Backend: "webhook", Server: server.URL},
+		config.Destination{Name: "audit", Backend: "file", Params: map[string]string{"path": logPath}},
+	)
+
+	server.status.Store(http.StatusServiceUnavailable)
+	if err := deliver(&notifier.Message{Title: "first"}); err == nil {
+		t.Fatal("deliver() succeeded while the server was down")
+	}
+	entries, _ := ob.List()
+	if len(entries) != 1 || entries[0].Destination.Name != "hook" || entries[0].Message.Title != "first" {
+		t.Fatalf("outbox = %+v, want the first message queued for hook", entries)
+	}
+
+	server.status.Store(0)
+	if err := deliver(&notifier.Message{Title: "second"}); err != nil {
+		t.Fatalf("deliver() = %v", err)
+	}
+	if got := server.received(); strings.Join(got, ",") != "first,second" {
+		t.Errorf("server received %q, want the queued message before the new one", got)
+	}
+	if entries, _ := ob.List(); len(entries) != 0 {
+		t.Errorf("outbox still holds %d entries", len(entries))
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var rec struct{ Title, Status string }
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatal(err)
+		}
+		statuses = append(statuses, rec.Title+":"+rec.Status)
+	}
+	if got, want := strings.Join(statuses, ","), "first:failed,first:sent,second:sent"; got != want {
+		t.Errorf("audit log = %s, want %s", got, want)
+	}
+}
+
+func TestDeliver_DoesNotQueueRejected(t *testing.T) {
+	server := newWebhookServer(t)
+	ob := useConfig(t, config.Destination{Name: "hook", Backend: "webhook", Server: server.URL})
+
+	server.status.Store(http.StatusUnauthorized)
+	if err := deliver(&notifier.Message{Title: "first"}); err == nil {
+		t.Fatal("deliver() succeeded while the server rejected it")
+	}
+	if entries, _ := ob.List(); len(entries) != 0 {
+		t.Errorf("outbox holds %d entries, want a rejected message not to be queued", len(entries))
+	}
+}
+
+func TestDeliver_UnnamedDestinations(t *testing.T) {
+	server := newWebhookServer(t)
+	ob := useConfig(t,
+		config.Destination{Backend: "webhook", Server: server.URL},
+		config.Destination{Backend: "webhook", Server: server.URL},
+	)
+
+	server.status.Store(http.StatusServiceUnavailable)
+	_ = deliver(&notifier.Message{Title: "first"})
+	entries, _ := ob.List()
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Destination.Name)
+	}
+	if got := strings.Join(names, ","); got != "webhook-1,webhook-2" {
+		t.Errorf("queued for %s, want webhook-1,webhook-2", got)
+	}
+}
+
+func TestDeliver_PriorityPrecedence(t *testing.T) {
+	server := newWebhookServer(t)
+	useConfig(t, config.Destination{Name: "hook", Backend: "webhook", Server: server.URL, Priority: "low"})
+	t.Cleanup(func() { flagPriority = "" })
+
+	// The destination's priority overrides the config default, but not
+	// one given on the command line or in the environment.
+	_ = deliver(&notifier.Message{Priority: "default"})
+	flagPriority = "max"
+	_ = deliver(&notifier.Message{Priority: "max"})
+	flagPriority = ""
+	t.Setenv("TN_PRIORITY", "high")
+	_ = deliver(&notifier.Message{Priority: "high"})
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if got := strings.Join(server.priorities, ","); got != "low,max,high" {
+		t.Errorf("priorities = %s, want low,max,high", got)
+	}
+}
+
+func TestFlushOutbox(t *testing.T) {
+	up, down, rejecting := newWebhookServer(t), newWebhookServer(t), newWebhookServer(t)
+	down.status.Store(http.StatusBadGateway)
+	rejecting.status.Store(http.StatusNotFound)
+
+	ob := outbox.New(t.TempDir())
+	for _, d := range []config.Destination{
+		{Name: "rejecting", Backend: "webhook", Server: rejecting.URL},
+		{Name: "down", Backend: "webhook", Server: down.URL},
+		{Name: "up", Backend: "webhook", Server: up.URL},
+	} {
+		if _, err := ob.Add(d, &notifier.Message{Title: d.Name}, 1, errors.New("offline")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	once := notifier.RetryPolicy{Attempts: 1}
+
+	// A quiet flush stops at the first network failure.
+	sent, left, err := flushOutbox(ob, once, nil, false)
+	if err != nil || sent != 0 || left != 2 {
+		t.Errorf("flushOutbox() = %d sent, %d left, %v; want 0 sent, 2 left", sent, left, err)
+	}
+	entries, _ := ob.List()
+	if len(entries) != 2 || entries[0].Destination.Name != "down" || entries[0].Attempts != 2 {
+		t.Fatalf("outbox = %+v, want the rejected entry dropped and down's attempts counted", entries)
+	}
+
+	sent, left, err = flushOutbox(ob, once, nil, true)
+	if err != nil || sent != 1 || left != 1 {
+		t.Errorf("flushOutbox() = %d sent, %d left, %v; want 1 sent, 1 left", sent, left, err)
+	}
+	if got := up.received(); len(got) != 1 || got[0] != "up" {
+		t.Errorf("up received %q", got)
+	}
+	if entries, _ := ob.List(); len(entries) != 1 || entries[0].Destination.Name != "down" {
+		t.Errorf("outbox = %+v, want only the entry for down", entries)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxTailBytes bounds how much output a tailBuffer keeps, whatever the
+// line count.
+const maxTailBytes = 8 * 1024
+
+// tailBuffer is an io.Writer that keeps only the end of what is written to
+// it. It is safe for concurrent use, so stdout and stderr can share one.
+type tailBuffer struct {
+	lines int
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func newTailBuffer(lines int) *tailBuffer {
+	return &tailBuffer{lines: lines}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - maxTailBytes; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the last lines written, without a trailing newline.
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := strings.TrimRight(string(t.buf), "\r\n")
+	lines := strings.Split(s, "\n")
+	if len(lines) > t.lines {
+		lines = lines[len(lines)-t.lines:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTailBuffer(t *testing.T) {
+	tb := newTailBuffer(3)
+	for _, chunk := range []string{"one\ntwo\n", "thr", "ee\nfour\n", "five\n"} {
+		_, _ = tb.Write([]byte(chunk))
+	}
+
+	if got, want := tb.String(), "three\nfour\nfive"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestTailBuffer_ByteLimit(t *testing.T) {
+	tb := newTailBuffer(1000)
+	_, _ = tb.Write([]byte(strings.Repeat("x", maxTailBytes) + "\nlast line"))
+
+	got := tb.String()
+	if len(got) > maxTailBytes {
+		t.Errorf("len(String()) = %d, want at most %d", len(got), maxTailBytes)
+	}
+	if !strings.HasSuffix(got, "\nlast line") {
+		t.Errorf("String() should end with the last line written, got ...%q", got[len(got)-20:])
+	}
+}
@@ -40,7 +40,7 @@ func runPid(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("watching process: %w", err)
 	}
 
-	duration := formatDuration(elapsed)
+	duration := notifier.FormatDuration(elapsed)
 	title := "🏁 Process Exited"
 	body := fmt.Sprintf("PID %d exited after %s", pid, duration)
 	tags := "checkered_flag"
@@ -50,20 +50,12 @@ func runPid(cmd *cobra.Command, args []string) error {
 	}
 
 	msg := &notifier.Message{
-		Server:   cfg.Server,
-		Topic:    cfg.Topic,
 		Title:    title,
 		Body:     body,
 		Priority: cfg.Priority,
 		Tags:     tags,
-		Token:    cfg.Token,
 	}
 
-	if notifyErr := notifier.Send(msg); notifyErr != nil {
-		fmt.Fprintf(os.Stderr, "tn: notification failed: %v\n", notifyErr)
-		return notifyErr
-	}
-
-	fmt.Fprintf(os.Stderr, "tn: PID %d finished — notification sent → %s/%s\n", pid, cfg.Server, cfg.Topic)
-	return nil
+	fmt.Fprintf(os.Stderr, "tn: PID %d finished after %s\n", pid, duration)
+	return deliver(msg)
 }
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lee/term_notify/internal/notifier"
+	"github.com/spf13/cobra"
+)
+
+var remindCmd = &cobra.Command{
+	Use:   "remind <when> <message>",
+	Short: "Schedule a reminder for later",
+	Long: `Schedules a notification to be delivered later. The server holds the
+message, so your machine does not need to be on when it is due.
+
+<when> can be a duration ("30m", "1h30m", "2d"), a time of day ("9am",
+"17:30", "tomorrow 9am", "friday 10:15am") or an RFC 3339 timestamp.
+Quote it if it contains spaces. ntfy schedules up to 3 days ahead.
+
+Examples:
+  tn remind 30m "Take the laundry out"
+  tn remind "tomorrow 9am" Call the bank
+  tn remind 2026-12-24T18:00:00+01:00 "Wrap presents"`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runRemind,
+}
+
+func init() {
+	addMessageFlags(remindCmd)
+	rootCmd.AddCommand(remindCmd)
+}
+
+func runRemind(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	at, err := parseWhen(args[0], now)
+	if err != nil {
+		return err
+	}
+	actions, err := actionFlags()
+	if err != nil {
+		return err
+	}
+
+	tags := "alarm_clock"
+	if userTags := getEffectiveTags(); userTags != "" {
+		tags = tags + "," + userTags
+	}
+
+	msg := &notifier.Message{
+		Title:    "⏰ Reminder",
+		Body:     strings.Join(args[1:], " "),
+		Priority: cfg.Priority,
+		Tags:     tags,
+		Delay:    at.Sub(now),
+	}
+	applyMessageFlags(msg, actions)
+
+	fmt.Fprintf(os.Stderr, "tn: reminder due %s\n", at.Format("Mon Jan 2 15:04 MST"))
+	return deliver(msg)
+}
+
+var (
+	dayDuration = regexp.MustCompile(`^(\d+)d(.*)$`)
+	clockTime   = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+// parseWhen turns a reminder time into an absolute time after now. It
+// accepts Go durations plus a "d" suffix for days, RFC 3339 timestamps,
+// and an optional day ("today", "tomorrow" or a weekday) followed by a
+// time of day. A bare time of day that has already passed means tomorrow,
+// and today's weekday at a time that has passed means next week.
+func parseWhen(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return checkFuture(t, now)
+	}
+
+	s = strings.TrimPrefix(strings.ToLower(s), "in ")
+	if d, ok := parseDays(s); ok {
+		return checkFuture(now.Add(d), now)
+	}
+
+	day, clock, _ := strings.Cut(s, " ")
+	var target time.Time
+	explicitDay, weekly := true, false
+	switch day {
+	case "today":
+		target = now
+	case "tomorrow":
+		target = now.AddDate(0, 0, 1)
+	default:
+		if wd, ok := weekday(day); ok {
+			target = now.AddDate(0, 0, (int(wd)-int(now.Weekday())+7)%7)
+			weekly = true
+		} else {
+			target, clock, explicitDay = now, s, false
+		}
+	}
+
+	hour, minute := 9, 0
+	if clock = strings.TrimSpace(clock); clock != "" {
+		var err error
+		if hour, minute, err = parseClock(clock); err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q: use e.g. 30m, 9am, 17:30, \"tomorrow 9am\" or RFC 3339", s)
+		}
+	} else if !explicitDay {
+		return time.Time{}, fmt.Errorf("invalid time %q", s)
+	}
+
+	t := time.Date(target.Year(), target.Month(), target.Day(), hour, minute, 0, 0, now.Location())
+	switch {
+	case !explicitDay && !t.After(now):
+		t = t.AddDate(0, 0, 1)
+	case weekly && !t.After(now):
+		// Today's weekday, but the time has passed.
+		t = t.AddDate(0, 0, 7)
+	}
+	return checkFuture(t, now)
+}
+
+// parseDays parses a Go duration, allowing a leading number of days such
+// as "2d" or "1d12h".
+func parseDays(s string) (time.Duration, bool) {
+	var days time.Duration
+	if m := dayDuration.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, false
+		}
+		days, s = time.Duration(n)*24*time.Hour, m[2]
+		if s == "" {
+			return days, true
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, false
+	}
+	return days + d, true
+}
+
+// parseClock reads "9", "9am", "9:30pm" or "21:30".
+func parseClock(s string) (hour, minute int, err error) {
+	m := clockTime.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, fmt.Errorf("not a time of day")
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("hour out of range")
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("time out of range")
+	}
+	return hour, minute, nil
+}
+
+func weekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func checkFuture(t, now time.Time) (time.Time, error) {
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("%s is in the past", t.Format(time.RFC3339))
+	}
+	return t, nil
+}
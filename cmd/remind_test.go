@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWhen(t *testing.T) {
+	// Wednesday 14:20 local time.
+	now := time.Date(2026, 10, 14, 14, 20, 0, 0, time.Local)
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"30m", now.Add(30 * time.Minute)},
+		{"in 1h30m", now.Add(90 * time.Minute)},
+		{"2d", now.Add(48 * time.Hour)},
+		{"1d12h", now.Add(36 * time.Hour)},
+		{"5pm", time.Date(2026, 10, 14, 17, 0, 0, 0, time.Local)},
+		{"9am", time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)}, // already past today
+		{"17:30", time.Date(2026, 10, 14, 17, 30, 0, 0, time.Local)},
+		{"tomorrow 9am", time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)},
+		{"Tomorrow 12:15 PM", time.Date(2026, 10, 15, 12, 15, 0, 0, time.Local)},
+		{"tomorrow", time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)},
+		{"today 11pm", time.Date(2026, 10, 14, 23, 0, 0, 0, time.Local)},
+		{"friday 10:15am", time.Date(2026, 10, 16, 10, 15, 0, 0, time.Local)},
+		{"wed 8am", time.Date(2026, 10, 21, 8, 0, 0, 0, time.Local)},  // next week
+		{"wed 5pm", time.Date(2026, 10, 14, 17, 0, 0, 0, time.Local)}, // later today
+		{"wednesday", time.Date(2026, 10, 21, 9, 0, 0, 0, time.Local)},
+		{"12am", time.Date(2026, 10, 15, 0, 0, 0, 0, time.Local)},
+		{"2026-10-20T08:00:00Z", time.Date(2026, 10, 20, 8, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseWhen(tt.in, now)
+			if err != nil {
+				t.Fatalf("parseWhen() returned unexpected error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseWhen() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWhen_Errors(t *testing.T) {
+	now := time.Date(2026, 10, 14, 14, 20, 0, 0, time.Local)
+
+	for _, in := range []string{
+		"soon",
+		"today 9am", // in the past
+		"2020-01-01T00:00:00Z",
+		"13pm",
+		"25:00",
+		"tomorrow noonish",
+		"-5m",
+	} {
+		if got, err := parseWhen(in, now); err == nil {
+			t.Errorf("parseWhen(%q) = %v, want error", in, got)
+		}
+	}
+}
@@ -13,18 +13,22 @@ var (
 	cfgErr error
 
 	// Flag overrides
+	flagURL      string
+	flagBackend  string
 	flagServer   string
 	flagTopic    string
 	flagPriority string
+	flagToken    string
 	flagTags     string
+	flagRetries  int
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "tn",
 	Short: "term_notify — get notified when terminal commands finish",
-	Long: `term_notify (tn) sends push notifications via ntfy when your
-terminal commands complete. Wrap a command, watch a PID, or
-send a quick notification.`,
+	Long: `term_notify (tn) sends push notifications via ntfy (or another
+configured backend) when your terminal commands complete. Wrap a
+command, watch a PID, or send a quick notification.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 }
@@ -40,10 +44,14 @@ func Execute() {
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVarP(&flagServer, "server", "s", "", "ntfy server (default: ntfy.sh)")
+	rootCmd.PersistentFlags().StringVar(&flagURL, "url", "", "destination URL, e.g. ntfy://host/topic (replaces backend/server/topic/token)")
+	rootCmd.PersistentFlags().StringVarP(&flagBackend, "backend", "b", "", "notification backend (default: ntfy)")
+	rootCmd.PersistentFlags().StringVarP(&flagServer, "server", "s", "", "backend server (default: ntfy.sh)")
 	rootCmd.PersistentFlags().StringVarP(&flagTopic, "topic", "t", "", "ntfy topic name")
 	rootCmd.PersistentFlags().StringVarP(&flagPriority, "priority", "p", "", "notification priority (min, low, default, high, max)")
+	rootCmd.PersistentFlags().StringVar(&flagToken, "token", "", "auth token for the backend")
 	rootCmd.PersistentFlags().StringVar(&flagTags, "tags", "", "comma-separated tags/emojis")
+	rootCmd.PersistentFlags().IntVar(&flagRetries, "retries", 0, "times to retry a failed delivery (default 3)")
 }
 
 func initConfig() {
@@ -53,7 +61,16 @@ func initConfig() {
 		cfg = config.DefaultConfig()
 	}
 
-	// CLI flags override config values
+	// CLI flags override config values. A URL describes a whole
+	// destination, so individual backend flags set aside a configured one.
+	if flagURL != "" {
+		cfg.URL = flagURL
+	} else if flagBackend != "" || flagServer != "" || flagTopic != "" || flagToken != "" {
+		cfg.URL = ""
+	}
+	if flagBackend != "" {
+		cfg.Backend = flagBackend
+	}
 	if flagServer != "" {
 		cfg.Server = flagServer
 	}
@@ -63,6 +80,12 @@ func initConfig() {
 	if flagPriority != "" {
 		cfg.Priority = flagPriority
 	}
+	if flagToken != "" {
+		cfg.Token = flagToken
+	}
+	if rootCmd.PersistentFlags().Changed("retries") {
+		cfg.Retries = flagRetries
+	}
 }
 
 // getEffectiveTags returns the tags to use — flag takes precedence.
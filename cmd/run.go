@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -18,15 +20,30 @@ var runCmd = &cobra.Command{
 	Long: `Executes the given command, waits for it to complete, then sends
 a push notification with the result, exit code, and duration.
 
+Flags for tn must come before the command; everything from the command
+on is passed to it untouched.
+
 Examples:
   tn run npm run build
   tn run ping -n 5 127.0.0.1
-  tn -t my-builds run make -j8`,
-	DisableFlagParsing: true,
-	RunE:               runRun,
+  tn -t my-builds run make -j8
+  tn run --tail 20 make test     # include the last 20 lines of output
+  tn run --attach-log ./train.sh # attach the full output (ntfy)
+  tn run --click https://ci.example.com/job/42 make`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runRun,
 }
 
+var (
+	runTail      int
+	runAttachLog bool
+)
+
 func init() {
+	runCmd.Flags().IntVar(&runTail, "tail", 0, "include the last N lines of output in the notification")
+	runCmd.Flags().BoolVar(&runAttachLog, "attach-log", false, "attach the command's output as a file (ntfy)")
+	addMessageFlags(runCmd)
+	runCmd.Flags().SetInterspersed(false)
 	rootCmd.AddCommand(runCmd)
 }
 
@@ -35,6 +52,11 @@ func runRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no command specified — usage: tn run <command> [args...]")
 	}
 
+	actions, err := actionFlags()
+	if err != nil {
+		return err
+	}
+
 	// Build the command
 	name := args[0]
 	var cmdArgs []string
@@ -52,15 +74,39 @@ func runRun(cmd *cobra.Command, args []string) error {
 		proc = exec.Command("sh", "-c", shellCmd) // #nosec G204
 	}
 
-	proc.Stdout = os.Stdout
-	proc.Stderr = os.Stderr
+	// Capturing output means the child writes to a pipe rather than the
+	// terminal, so it is only done when asked for.
+	stdout := []io.Writer{os.Stdout}
+	stderr := []io.Writer{os.Stderr}
 	proc.Stdin = os.Stdin
 
+	var tail *tailBuffer
+	if runTail > 0 {
+		tail = newTailBuffer(runTail)
+		stdout = append(stdout, tail)
+		stderr = append(stderr, tail)
+	}
+
+	var logFile *os.File
+	if runAttachLog {
+		var err error
+		if logFile, err = os.CreateTemp("", "tn-*.log"); err != nil {
+			return fmt.Errorf("creating output log: %w", err)
+		}
+		defer os.Remove(logFile.Name()) //nolint:errcheck
+		defer logFile.Close()           //nolint:errcheck
+		stdout = append(stdout, logFile)
+		stderr = append(stderr, logFile)
+	}
+
+	proc.Stdout = io.MultiWriter(stdout...)
+	proc.Stderr = io.MultiWriter(stderr...)
+
 	displayCmd := strings.Join(args, " ")
 	fmt.Fprintf(os.Stderr, "tn: running %q\n", displayCmd)
 
 	start := time.Now()
-	err := proc.Run()
+	err = proc.Run()
 	elapsed := time.Since(start)
 
 	exitCode := 0
@@ -73,7 +119,7 @@ func runRun(cmd *cobra.Command, args []string) error {
 	}
 
 	// Build notification
-	duration := formatDuration(elapsed)
+	duration := notifier.FormatDuration(elapsed)
 	var title, body, tags string
 
 	if exitCode == 0 {
@@ -91,39 +137,51 @@ func runRun(cmd *cobra.Command, args []string) error {
 		tags = tags + "," + userTags
 	}
 
+	host, _ := os.Hostname()
+	cwd, _ := os.Getwd()
+
 	msg := &notifier.Message{
-		Server:   cfg.Server,
-		Topic:    cfg.Topic,
 		Title:    title,
 		Body:     body,
 		Priority: cfg.Priority,
 		Tags:     tags,
-		Token:    cfg.Token,
+		Run: &notifier.RunInfo{
+			Command:  displayCmd,
+			ExitCode: exitCode,
+			Duration: elapsed,
+			Host:     host,
+			Cwd:      cwd,
+		},
 	}
-
-	if notifyErr := notifier.Send(msg); notifyErr != nil {
-		fmt.Fprintf(os.Stderr, "tn: notification failed: %v\n", notifyErr)
-	} else {
-		fmt.Fprintf(os.Stderr, "tn: notification sent → %s/%s\n", cfg.Server, cfg.Topic)
+	applyMessageFlags(msg, actions)
+	if tail != nil {
+		msg.Run.Output = tail.String()
 	}
+	if logFile != nil {
+		msg.Attachment = &notifier.Attachment{Name: logName(name), Path: logFile.Name()}
+	}
+
+	// Per-destination outcomes are reported by deliver; a failed
+	// notification must not mask the child's exit code.
+	_ = deliver(msg)
 
 	// Exit with the same code as the child process
 	if exitCode != 0 {
+		if logFile != nil {
+			_ = logFile.Close()
+			_ = os.Remove(logFile.Name())
+		}
 		os.Exit(exitCode)
 	}
 	return nil
 }
 
-func formatDuration(d time.Duration) string {
-	if d < time.Minute {
-		return fmt.Sprintf("%.1fs", d.Seconds())
-	}
-	m := int(d.Minutes())
-	s := int(d.Seconds()) % 60
-	if d < time.Hour {
-		return fmt.Sprintf("%dm %ds", m, s)
+// logName names the attached output log after the command, e.g. "make.log".
+func logName(command string) string {
+	base := filepath.Base(command)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	if base == "" || base == "." || base == string(filepath.Separator) {
+		base = "output"
 	}
-	h := int(d.Hours())
-	m = m % 60
-	return fmt.Sprintf("%dh %dm %ds", h, m, s)
+	return base + ".log"
 }
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/lee/term_notify/internal/config"
+	"github.com/lee/term_notify/internal/notifier"
+	"github.com/spf13/cobra"
+)
+
+// Message extras shared by the commands that send notifications.
+var (
+	flagClick   string
+	flagIcon    string
+	flagAttach  string
+	flagActions []string
+)
+
+// addMessageFlags registers --click, --icon, --attach and --action on cmd.
+func addMessageFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&flagClick, "click", "", "URL to open when the notification is tapped")
+	cmd.Flags().StringVar(&flagIcon, "icon", "", "URL of an icon to show with the notification")
+	cmd.Flags().StringVar(&flagAttach, "attach", "", "URL of a file to attach")
+	cmd.Flags().StringArrayVar(&flagActions, "action", nil, `action button, e.g. "view, Open logs, https://ci/job/42" (repeatable)`)
+}
+
+// actionFlags parses the --action values, so that mistakes are reported
+// before any work is done.
+func actionFlags() ([]notifier.Action, error) {
+	var actions []notifier.Action
+	for _, s := range flagActions {
+		a, err := notifier.ParseAction(s)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// applyMessageFlags sets the --click, --icon and --attach values and the
+// parsed actions on msg.
+func applyMessageFlags(msg *notifier.Message, actions []notifier.Action) {
+	msg.Click = flagClick
+	msg.Icon = flagIcon
+	msg.Attach = flagAttach
+	msg.Actions = actions
+}
+
+// destinations returns the configured destinations. Passing --url,
+// --backend, --server, --topic or --token selects a one-off destination
+// built from the top-level settings instead.
+func destinations() []config.Destination {
+	if len(cfg.Destinations) == 0 || flagURL != "" || flagBackend != "" || flagServer != "" || flagTopic != "" || flagToken != "" {
+		return []config.Destination{cfg.DefaultDestination()}
+	}
+	return cfg.NamedDestinations()
+}
+
+// priorityGiven reports whether --priority or TN_PRIORITY set the
+// priority, which then takes precedence over each destination's own.
+func priorityGiven() bool {
+	return flagPriority != "" || os.Getenv("TN_PRIORITY") != ""
+}
+
+// newNotifier builds and validates the backend for a destination.
+func newNotifier(d config.Destination) (notifier.Notifier, error) {
+	n, err := notifier.New(d.Backend, notifier.Options{
+		Server:   d.Server,
+		Topic:    d.Topic,
+		Token:    d.Token,
+		User:     d.User,
+		Password: d.Password,
+		Params:   d.Params,
+		Headers:  d.Headers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// deliver sends msg to every destination concurrently and reports each
+// outcome on stderr. Deliveries that fail for want of a network are kept
+// in the outbox, after anything already there has been resent. It returns
+// an error if any destination failed.
+func deliver(msg *notifier.Message) error {
+	resendQueued()
+	dests := destinations()
+
+	var ready []notifier.Destination
+	var resolved []config.Destination
+	var targets []string
+	failed := 0
+
+	for _, d := range dests {
+		if priorityGiven() {
+			// The command line or environment asked for this priority.
+			d.Priority = ""
+		}
+		d, err := d.Resolve()
+		var n notifier.Notifier
+		if err == nil {
+			n, err = newNotifier(d)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tn: notification failed → %s (%s): %v\n", d.Name, d.Backend, err)
+			failed++
+			continue
+		}
+		targets = append(targets, describe(n))
+		ready = append(ready, notifier.Destination{Name: d.Name, Priority: d.Priority, Notifier: n, Retry: retryPolicy()})
+		resolved = append(resolved, d)
+	}
+
+	for i, r := range notifier.Dispatch(context.Background(), ready, msg) {
+		if r.Err != nil {
+			fmt.Fprintf(os.Stderr, "tn: notification failed → %s (%s)%s: %v\n", r.Destination, r.Backend, attempts(r), r.Err)
+			failed++
+			if notifier.Retryable(r.Err) {
+				queue(resolved[i], msg, r)
+			}
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "tn: notification sent → %s (%s)%s\n", r.Destination, targets[i], attempts(r))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d destinations failed", failed, len(dests))
+	}
+	return nil
+}
+
+// retryPolicy returns the configured retry behavior.
+func retryPolicy() notifier.RetryPolicy {
+	p := notifier.DefaultRetry
+	p.Attempts = max(cfg.Retries, 0) + 1
+	p.Deadline = cfg.RetryDeadline
+	return p
+}
+
+// attempts notes how many tries a delivery took, when it took more than one.
+func attempts(r notifier.Result) string {
+	if r.Attempts <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" after %d attempts", r.Attempts)
+}
+
+// describe names a notifier for humans, preferring its String form.
+func describe(n notifier.Notifier) string {
+	if s, ok := n.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return n.Name()
+}
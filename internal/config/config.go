@@ -5,23 +5,146 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds the application configuration.
+//
+// The top-level backend settings describe a single default destination.
+// When Destinations is non-empty, notifications go to every entry instead.
+// URL, when set, replaces Backend, Server, Topic and Token.
 type Config struct {
-	Server   string `yaml:"server"`
-	Topic    string `yaml:"topic"`
-	Priority string `yaml:"priority"`
-	Token    string `yaml:"token"`
+	URL          string            `yaml:"url,omitempty"`
+	Backend      string            `yaml:"backend"`
+	Server       string            `yaml:"server"`
+	Topic        string            `yaml:"topic"`
+	Priority     string            `yaml:"priority"`
+	Token        string            `yaml:"token"`
+	User         string            `yaml:"user,omitempty"`
+	Password     string            `yaml:"password,omitempty"`
+	Params       map[string]string `yaml:"params,omitempty"`
+	Headers      map[string]string `yaml:"headers,omitempty"`
+	Destinations []Destination     `yaml:"destinations,omitempty"`
+
+	// Retries is how many times a failed delivery is retried, and
+	// RetryDeadline how long tn keeps trying in total, per destination.
+	Retries       int           `yaml:"retries"`
+	RetryDeadline time.Duration `yaml:"retry_deadline"`
+}
+
+// Destination is a named place to deliver notifications to.
+//
+// URL is an Apprise-style alternative to the individual settings; see
+// ParseURL and Resolve.
+type Destination struct {
+	Name     string `yaml:"name"`
+	URL      string `yaml:"url,omitempty"`
+	Backend  string `yaml:"backend,omitempty"`
+	Server   string `yaml:"server,omitempty"`
+	Topic    string `yaml:"topic,omitempty"`
+	Priority string `yaml:"priority,omitempty"`
+	Token    string `yaml:"token,omitempty"`
+	User     string `yaml:"user,omitempty"`
+	Password string `yaml:"password,omitempty"`
+
+	// Params holds backend-specific settings, e.g. a Telegram parse mode.
+	Params map[string]string `yaml:"params,omitempty"`
+	// Headers holds extra HTTP headers for the webhook backend.
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+// DefaultDestination returns the destination described by the top-level
+// backend settings. Priority is left empty so the message priority applies.
+// The default ntfy.sh server is dropped for other backends, which would
+// otherwise mistake it for their own server. With a URL, the name is left
+// for Resolve to fill in from the URL's backend.
+func (c *Config) DefaultDestination() Destination {
+	if c.URL != "" {
+		return Destination{URL: c.URL, Params: c.Params, Headers: c.Headers}
+	}
+
+	d := Destination{
+		Name:     c.Backend,
+		Backend:  c.Backend,
+		Server:   c.Server,
+		Topic:    c.Topic,
+		Token:    c.Token,
+		User:     c.User,
+		Password: c.Password,
+		Params:   c.Params,
+		Headers:  c.Headers,
+	}
+	if d.Backend != "ntfy" && d.Server == DefaultConfig().Server {
+		d.Server = ""
+	}
+	return d
+}
+
+// NamedDestinations returns Destinations with every entry named, so that
+// their results can be told apart. An unnamed entry is called after its
+// backend, followed by its position (e.g. "ntfy-2") when that name is
+// shared.
+func (c *Config) NamedDestinations() []Destination {
+	dests := slices.Clone(c.Destinations)
+	backends := make([]string, len(dests))
+	uses := map[string]int{}
+	for i, d := range dests {
+		if d.Name != "" {
+			uses[d.Name]++
+			continue
+		}
+		backends[i] = d.Backend
+		if d.URL != "" {
+			backends[i] = "url"
+			if r, err := ParseURL(d.URL); err == nil {
+				backends[i] = r.Backend
+			}
+		}
+		if backends[i] == "" {
+			backends[i] = "destination"
+		}
+		uses[backends[i]]++
+	}
+
+	for i := range dests {
+		if dests[i].Name != "" {
+			continue
+		}
+		dests[i].Name = backends[i]
+		if uses[backends[i]] > 1 {
+			dests[i].Name = fmt.Sprintf("%s-%d", backends[i], i+1)
+		}
+	}
+	return dests
+}
+
+// checkDestinations reports destinations that share a name.
+func (c *Config) checkDestinations() error {
+	seen := map[string]bool{}
+	for _, d := range c.Destinations {
+		if d.Name == "" {
+			continue
+		}
+		if seen[d.Name] {
+			return fmt.Errorf("more than one destination is named %q", d.Name)
+		}
+		seen[d.Name] = true
+	}
+	return nil
 }
 
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
-		Server:   "ntfy.sh",
-		Priority: "default",
+		Backend:       "ntfy",
+		Server:        "ntfy.sh",
+		Priority:      "default",
+		Retries:       3,
+		RetryDeadline: time.Minute,
 	}
 }
 
@@ -53,18 +176,28 @@ func ConfigPath() (string, error) {
 // Load reads the config file, applies env var overrides, and returns the config.
 // If the config file doesn't exist, returns defaults with env overrides.
 func Load() (*Config, error) {
+	cfg, err := LoadFile()
+	if err != nil {
+		return nil, err
+	}
+	applyEnvOverrides(cfg)
+	return cfg, nil
+}
+
+// LoadFile reads the config file as it is on disk, without env var
+// overrides, for commands that change it and save it back. If the config
+// file doesn't exist, returns defaults.
+func LoadFile() (*Config, error) {
 	cfg := DefaultConfig()
 
 	path, err := ConfigPath()
 	if err != nil {
-		applyEnvOverrides(cfg)
 		return cfg, nil
 	}
 
 	data, err := os.ReadFile(path) // #nosec G304 — config path is trusted
 	if err != nil {
 		if os.IsNotExist(err) {
-			applyEnvOverrides(cfg)
 			return cfg, nil
 		}
 		return nil, fmt.Errorf("reading config file: %w", err)
@@ -73,8 +206,9 @@ func Load() (*Config, error) {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("parsing config file: %w", err)
 	}
-
-	applyEnvOverrides(cfg)
+	if err := cfg.checkDestinations(); err != nil {
+		return nil, fmt.Errorf("invalid config file: %w", err)
+	}
 	return cfg, nil
 }
 
@@ -103,6 +237,12 @@ func Save(cfg *Config) error {
 
 // applyEnvOverrides overrides config values with environment variables if set.
 func applyEnvOverrides(cfg *Config) {
+	if v := os.Getenv("TN_URL"); v != "" {
+		cfg.URL = v
+	}
+	if v := os.Getenv("TN_BACKEND"); v != "" {
+		cfg.Backend = v
+	}
 	if v := os.Getenv("TN_SERVER"); v != "" {
 		cfg.Server = v
 	}
@@ -112,7 +252,16 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("TN_TOKEN"); v != "" {
 		cfg.Token = v
 	}
+	if v := os.Getenv("TN_USER"); v != "" {
+		cfg.User = v
+	}
+	if v := os.Getenv("TN_PASSWORD"); v != "" {
+		cfg.Password = v
+	}
 	if v := os.Getenv("TN_PRIORITY"); v != "" {
 		cfg.Priority = v
 	}
+	if v, err := strconv.Atoi(os.Getenv("TN_RETRIES")); err == nil && v >= 0 {
+		cfg.Retries = v
+	}
 }
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -11,6 +13,9 @@ import (
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 
+	if cfg.Backend != "ntfy" {
+		t.Errorf("DefaultConfig().Backend = %q, want %q", cfg.Backend, "ntfy")
+	}
 	if cfg.Server != "ntfy.sh" {
 		t.Errorf("DefaultConfig().Server = %q, want %q", cfg.Server, "ntfy.sh")
 	}
@@ -27,6 +32,7 @@ func TestDefaultConfig(t *testing.T) {
 
 func TestApplyEnvOverrides(t *testing.T) {
 	t.Run("all env vars set", func(t *testing.T) {
+		t.Setenv("TN_BACKEND", "custom-backend")
 		t.Setenv("TN_SERVER", "custom.ntfy.example.com")
 		t.Setenv("TN_TOPIC", "test-topic")
 		t.Setenv("TN_TOKEN", "secret-token-123")
@@ -35,6 +41,9 @@ func TestApplyEnvOverrides(t *testing.T) {
 		cfg := DefaultConfig()
 		applyEnvOverrides(cfg)
 
+		if cfg.Backend != "custom-backend" {
+			t.Errorf("Backend = %q, want %q", cfg.Backend, "custom-backend")
+		}
 		if cfg.Server != "custom.ntfy.example.com" {
 			t.Errorf("Server = %q, want %q", cfg.Server, "custom.ntfy.example.com")
 		}
@@ -51,6 +60,7 @@ func TestApplyEnvOverrides(t *testing.T) {
 
 	t.Run("empty env vars keep defaults", func(t *testing.T) {
 		// Ensure these are unset for this test
+		t.Setenv("TN_BACKEND", "")
 		t.Setenv("TN_SERVER", "")
 		t.Setenv("TN_TOPIC", "")
 		t.Setenv("TN_TOKEN", "")
@@ -59,6 +69,9 @@ func TestApplyEnvOverrides(t *testing.T) {
 		cfg := DefaultConfig()
 		applyEnvOverrides(cfg)
 
+		if cfg.Backend != "ntfy" {
+			t.Errorf("Backend = %q, want %q", cfg.Backend, "ntfy")
+		}
 		if cfg.Server != "ntfy.sh" {
 			t.Errorf("Server = %q, want %q", cfg.Server, "ntfy.sh")
 		}
@@ -90,6 +103,7 @@ func TestConfigYAMLRoundTrip(t *testing.T) {
 	tmpFile := filepath.Join(tmpDir, "config.yaml")
 
 	original := &Config{
+		Backend:  "ntfy",
 		Server:   "my-server.example.com",
 		Topic:    "my-topic",
 		Priority: "high",
@@ -115,6 +129,9 @@ func TestConfigYAMLRoundTrip(t *testing.T) {
 		t.Fatalf("failed to unmarshal config: %v", err)
 	}
 
+	if loaded.Backend != original.Backend {
+		t.Errorf("Backend = %q, want %q", loaded.Backend, original.Backend)
+	}
 	if loaded.Server != original.Server {
 		t.Errorf("Server = %q, want %q", loaded.Server, original.Server)
 	}
@@ -128,3 +145,146 @@ func TestConfigYAMLRoundTrip(t *testing.T) {
 		t.Errorf("Token = %q, want %q", loaded.Token, original.Token)
 	}
 }
+
+func TestDefaultDestination(t *testing.T) {
+	cfg := &Config{
+		Backend:  "ntfy",
+		Server:   "ntfy.example.com",
+		Topic:    "alerts",
+		Priority: "high",
+		Token:    "tk_abc",
+		Params:   map[string]string{"parse_mode": "HTML"},
+	}
+
+	d := cfg.DefaultDestination()
+
+	if d.Name != "ntfy" || d.Backend != "ntfy" {
+		t.Errorf("Name/Backend = %q/%q, want %q/%q", d.Name, d.Backend, "ntfy", "ntfy")
+	}
+	if d.Server != cfg.Server || d.Topic != cfg.Topic || d.Token != cfg.Token {
+		t.Errorf("DefaultDestination() = %+v, want settings copied from %+v", d, cfg)
+	}
+	if d.Params["parse_mode"] != "HTML" {
+		t.Errorf("Params = %v, want params copied from config", d.Params)
+	}
+	if d.Priority != "" {
+		t.Errorf("Priority = %q, want empty so the message priority applies", d.Priority)
+	}
+}
+
+func TestDefaultDestination_DropsNtfyServer(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Backend = "gotify"
+
+	if d := cfg.DefaultDestination(); d.Server != "" {
+		t.Errorf("Server = %q, want the ntfy.sh default dropped for gotify", d.Server)
+	}
+
+	cfg.Backend = "ntfy"
+	if d := cfg.DefaultDestination(); d.Server != "ntfy.sh" {
+		t.Errorf("Server = %q, want ntfy.sh kept for ntfy", d.Server)
+	}
+}
+
+func TestDestinationsYAML(t *testing.T) {
+	data := []byte(`
+backend: ntfy
+topic: fallback
+destinations:
+  - name: phone
+    backend: ntfy
+    topic: my-phone
+    priority: high
+  - name: self-hosted
+    backend: ntfy
+    server: ntfy.example.com
+    topic: builds
+    token: tk_secret
+  - name: telegram
+    backend: telegram
+    token: "123:ABC"
+    topic: "-100200300"
+    params:
+      parse_mode: MarkdownV2
+`)
+
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		t.Fatalf("failed to unmarshal config: %v", err)
+	}
+
+	if len(cfg.Destinations) != 3 {
+		t.Fatalf("len(Destinations) = %d, want 3", len(cfg.Destinations))
+	}
+	phone := cfg.Destinations[0]
+	if phone.Name != "phone" || phone.Topic != "my-phone" || phone.Priority != "high" {
+		t.Errorf("Destinations[0] = %+v", phone)
+	}
+	hosted := cfg.Destinations[1]
+	if hosted.Server != "ntfy.example.com" || hosted.Token != "tk_secret" {
+		t.Errorf("Destinations[1] = %+v", hosted)
+	}
+	if got := cfg.Destinations[2].Params["parse_mode"]; got != "MarkdownV2" {
+		t.Errorf("Destinations[2].Params[parse_mode] = %q, want %q", got, "MarkdownV2")
+	}
+}
+
+func TestNamedDestinations(t *testing.T) {
+	cfg := &Config{Destinations: []Destination{
+		{Name: "phone", Backend: "ntfy"},
+		{Backend: "ntfy"},
+		{URL: "ntfys://ntfy.example.com/builds"},
+		{Backend: "slack"},
+		{Name: "phone-2", Backend: "gotify"},
+	}}
+
+	var names []string
+	for _, d := range cfg.NamedDestinations() {
+		names = append(names, d.Name)
+	}
+	if got, want := strings.Join(names, ","), "phone,ntfy-2,ntfy-3,slack,phone-2"; got != want {
+		t.Errorf("names = %s, want %s", got, want)
+	}
+	if cfg.Destinations[1].Name != "" {
+		t.Error("NamedDestinations() changed the config")
+	}
+}
+
+func TestLoad_DuplicateNames(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("APPDATA", home)
+	path, err := ConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	data := "destinations:\n  - name: phone\n    topic: a\n  - name: phone\n    topic: b\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), `"phone"`) {
+		t.Errorf("Load() error = %v, want the duplicate name reported", err)
+	}
+}
+
+func TestRetryYAML(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal([]byte("retries: 0\nretry_deadline: 30s\n"), cfg); err != nil {
+		t.Fatalf("failed to unmarshal config: %v", err)
+	}
+	if cfg.Retries != 0 || cfg.RetryDeadline != 30*time.Second {
+		t.Errorf("retries = %d, deadline = %s, want 0 and 30s", cfg.Retries, cfg.RetryDeadline)
+	}
+
+	data, err := yaml.Marshal(DefaultConfig())
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+	if !strings.Contains(string(data), "retry_deadline: 1m0s") {
+		t.Errorf("marshaled config = %q, want a readable retry_deadline", data)
+	}
+}
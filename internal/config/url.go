@@ -0,0 +1,279 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// urlSchemes lists the Apprise-style schemes ParseURL understands.
+var urlSchemes = []string{
+	"discord", "gotify", "gotifys", "json", "jsons", "mailto", "mailtos",
+	"matrix", "matrixs", "mqtt", "mqtts", "ntfy", "ntfys", "opsgenie",
+	"pagerduty", "slack", "tgram",
+}
+
+// ParseURL turns an Apprise-style URL such as ntfy://token@host/topic,
+// gotify://host/token or tgram://bot-token/chat-id into the destination it
+// describes. Query parameters become backend params.
+func ParseURL(raw string) (Destination, error) {
+	scheme, rest, ok := strings.Cut(raw, "://")
+	if !ok {
+		return Destination{}, fmt.Errorf("invalid destination URL %q: missing scheme", raw)
+	}
+	scheme = strings.ToLower(scheme)
+
+	// Telegram bot tokens contain a colon that url.Parse would read as a
+	// port, so the path is split by hand.
+	if scheme == "tgram" {
+		return parseTelegramURL(rest)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Destination{}, fmt.Errorf("invalid destination URL: %w", err)
+	}
+
+	d := Destination{Params: map[string]string{}}
+	for k, v := range u.Query() {
+		d.Params[k] = v[len(v)-1]
+	}
+	user := u.User.Username()
+	password, hasPassword := u.User.Password()
+	segments := splitPath(u.Path)
+	httpScheme := "http://"
+	if strings.HasSuffix(scheme, "s") {
+		httpScheme = "https://"
+	}
+
+	switch scheme {
+	case "ntfy", "ntfys":
+		d.Backend = "ntfy"
+		if len(segments) == 0 {
+			// ntfy://topic publishes to ntfy.sh.
+			d.Server, d.Topic = "https://ntfy.sh", u.Host
+		} else {
+			d.Server, d.Topic = httpScheme+u.Host, strings.Join(segments, "/")
+		}
+		if hasPassword {
+			d.User, d.Password = user, password
+		} else {
+			d.Token = user
+		}
+
+	case "gotify", "gotifys":
+		if len(segments) == 0 {
+			return Destination{}, fmt.Errorf("gotify URL needs an app token: gotify://host/token")
+		}
+		d.Backend = "gotify"
+		d.Server = httpScheme + u.Host + prefixPath(segments[:len(segments)-1])
+		d.Token = segments[len(segments)-1]
+
+	case "mailto", "mailtos":
+		d.Backend = "email"
+		d.Server = u.Host
+		d.User, d.Password = user, password
+		if scheme == "mailtos" {
+			d.Params["tls"] = "tls"
+		}
+		// A login that is a full address doubles as the sender, and as
+		// the recipient unless the path names others. A bare user name
+		// says nothing about the mailbox, so from and to must be given.
+		self := ""
+		if strings.Contains(user, "@") {
+			self = user
+		}
+		if d.Params["from"] == "" && self != "" {
+			d.Params["from"] = self
+		}
+		if d.Params["to"] == "" {
+			switch {
+			case len(segments) > 0:
+				d.Params["to"] = strings.Join(segments, ",")
+			case self != "":
+				d.Params["to"] = self
+			}
+		}
+		if d.Params["from"] == "" || d.Params["to"] == "" {
+			return Destination{}, fmt.Errorf("mailto URL needs a sender and recipient: log in with a full address (user%%40example.com) or set ?from= and ?to=")
+		}
+
+	case "json", "jsons":
+		d.Backend = "webhook"
+		d.Params = map[string]string{}
+		// Apprise passes headers as +Name=value and keeps other
+		// parameters in the target URL.
+		headers, query, err := splitHeaders(u.RawQuery)
+		if err != nil {
+			return Destination{}, fmt.Errorf("invalid destination URL: %w", err)
+		}
+		d.Headers = headers
+		if v, ok := query["method"]; ok {
+			d.Params["method"] = v[len(v)-1]
+			delete(query, "method")
+		}
+		target := url.URL{Scheme: strings.TrimSuffix(httpScheme, "://"), User: u.User, Host: u.Host, Path: u.Path, RawQuery: query.Encode()}
+		d.Server = target.String()
+
+	case "discord":
+		if len(segments) != 1 {
+			return Destination{}, fmt.Errorf("discord URL must be discord://webhook_id/webhook_token")
+		}
+		d.Backend = "discord"
+		d.Server = "https://discord.com/api/webhooks/" + u.Host + "/" + segments[0]
+
+	case "slack":
+		if len(segments) != 2 {
+			return Destination{}, fmt.Errorf("slack URL must be slack://T…/B…/token")
+		}
+		d.Backend = "slack"
+		d.Server = "https://hooks.slack.com/services/" + u.Host + "/" + strings.Join(segments, "/")
+
+	case "matrix", "matrixs":
+		d.Backend = "matrix"
+		d.Server = httpScheme + u.Host
+		d.Token = user
+		// Room aliases start with '#', which url.Parse treats as the fragment.
+		d.Topic = strings.Join(segments, "/")
+		if u.Fragment != "" {
+			d.Topic = "#" + u.Fragment
+		}
+
+	case "mqtt", "mqtts":
+		d.Backend = "mqtt"
+		d.Server = scheme + "://" + u.Host
+		d.Topic = strings.Join(segments, "/")
+		d.User, d.Password = user, password
+
+	case "pagerduty", "opsgenie":
+		d.Backend = scheme
+		d.Token = u.Host
+
+	default:
+		return Destination{}, fmt.Errorf("unsupported destination URL scheme %q (supported: %s)",
+			scheme, strings.Join(urlSchemes, ", "))
+	}
+
+	if len(d.Params) == 0 {
+		d.Params = nil
+	}
+	if len(d.Headers) == 0 {
+		d.Headers = nil
+	}
+	return d, nil
+}
+
+// parseTelegramURL handles tgram://bot-token/chat-id.
+func parseTelegramURL(rest string) (Destination, error) {
+	rest, rawQuery, _ := strings.Cut(rest, "?")
+	token, chat, _ := strings.Cut(rest, "/")
+	chat = strings.Trim(chat, "/")
+	if token == "" || chat == "" {
+		return Destination{}, fmt.Errorf("telegram URL must be tgram://bot-token/chat-id")
+	}
+
+	d := Destination{Backend: "telegram", Token: token, Topic: chat}
+	if query, err := url.ParseQuery(rawQuery); err == nil && len(query) > 0 {
+		d.Params = map[string]string{}
+		for k, v := range query {
+			d.Params[k] = v[len(v)-1]
+		}
+	}
+	return d, nil
+}
+
+// Resolve expands the destination's URL, if any, into the backend, server,
+// topic and credentials it encodes. Name and priority are kept, and params
+// and headers set on the destination take precedence over those from the
+// URL.
+func (d Destination) Resolve() (Destination, error) {
+	if d.URL == "" {
+		return d, nil
+	}
+
+	r, err := ParseURL(d.URL)
+	if err != nil {
+		if d.Name == "" {
+			d.Name = "url"
+		}
+		return d, err
+	}
+
+	r.Name = d.Name
+	if r.Name == "" {
+		r.Name = r.Backend
+	}
+	r.URL = d.URL
+	r.Priority = d.Priority
+	r.Params = mergeMaps(r.Params, d.Params)
+	r.Headers = mergeMaps(r.Headers, d.Headers)
+	return r, nil
+}
+
+// mergeMaps returns base overlaid with override, or nil if both are empty.
+func mergeMaps(base, override map[string]string) map[string]string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// splitPath returns the non-empty, unescaped segments of p.
+func splitPath(p string) []string {
+	var segments []string
+	for _, s := range strings.Split(p, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// prefixPath joins segments back into a path with a leading slash, or ""
+// when there are none.
+func prefixPath(segments []string) string {
+	if len(segments) == 0 {
+		return ""
+	}
+	return path.Join(append([]string{"/"}, segments...)...)
+}
+
+// splitHeaders separates Apprise's +Name=value header parameters from the
+// rest of a raw query. The raw form is needed because url.ParseQuery reads
+// a literal + as an encoded space; an encoded %2B marks a header too.
+func splitHeaders(rawQuery string) (map[string]string, url.Values, error) {
+	headers := map[string]string{}
+	query := url.Values{}
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		name, isHeader := strings.CutPrefix(rawKey, "+")
+		key, err := url.QueryUnescape(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !isHeader {
+			key, isHeader = strings.CutPrefix(key, "+")
+		}
+		if isHeader {
+			headers[key] = value
+		} else {
+			query.Add(key, value)
+		}
+	}
+	return headers, query, nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want Destination
+	}{
+		{"ntfy://my-alerts", Destination{Backend: "ntfy", Server: "https://ntfy.sh", Topic: "my-alerts"}},
+		{"ntfys://tk_abc@ntfy.example.com/builds", Destination{Backend: "ntfy", Server: "https://ntfy.example.com", Topic: "builds", Token: "tk_abc"}},
+		{"ntfy://alice:pw@10.0.0.2:8080/builds", Destination{Backend: "ntfy", Server: "http://10.0.0.2:8080", Topic: "builds", User: "alice", Password: "pw"}},
+		{"gotifys://push.example.com/AppToken", Destination{Backend: "gotify", Server: "https://push.example.com", Token: "AppToken"}},
+		{"gotify://host/sub/path/AppToken", Destination{Backend: "gotify", Server: "http://host/sub/path", Token: "AppToken"}},
+		{"tgram://123456:ABC-def/-100200?parse_mode=HTML", Destination{Backend: "telegram", Token: "123456:ABC-def", Topic: "-100200", Params: map[string]string{"parse_mode": "HTML"}}},
+		{"mailto://me:pw@smtp.example.com?from=me@example.com&to=ops@example.com", Destination{Backend: "email", Server: "smtp.example.com", User: "me", Password: "pw", Params: map[string]string{"from": "me@example.com", "to": "ops@example.com"}}},
+		{"mailtos://me%40example.com:pw@smtp.example.com:465?to=ops@example.com", Destination{Backend: "email", Server: "smtp.example.com:465", User: "me@example.com", Password: "pw", Params: map[string]string{"from": "me@example.com", "to": "ops@example.com", "tls": "tls"}}},
+		{"mailto://me:pw@smtp.example.com/a@example.com/b@example.com?from=tn@example.com", Destination{Backend: "email", Server: "smtp.example.com", User: "me", Password: "pw", Params: map[string]string{"from": "tn@example.com", "to": "a@example.com,b@example.com"}}},
+		{"json://dash.local/api/jobs?method=PUT&%2BX-Key=s3cret&v=2", Destination{Backend: "webhook", Server: "http://dash.local/api/jobs?v=2", Params: map[string]string{"method": "PUT"}, Headers: map[string]string{"X-Key": "s3cret"}}},
+		{"jsons://dash.local/hook?+X-Key=s3cret&+Authorization=Bearer+abc&q=a+b", Destination{Backend: "webhook", Server: "https://dash.local/hook?q=a+b", Headers: map[string]string{"X-Key": "s3cret", "Authorization": "Bearer abc"}}},
+		{"discord://123/abc", Destination{Backend: "discord", Server: "https://discord.com/api/webhooks/123/abc"}},
+		{"slack://T000/B000/XXXX", Destination{Backend: "slack", Server: "https://hooks.slack.com/services/T000/B000/XXXX"}},
+		{"matrixs://syt_tok@matrix.example.org/#ops:example.org", Destination{Backend: "matrix", Server: "https://matrix.example.org", Token: "syt_tok", Topic: "#ops:example.org"}},
+		{"mqtts://tn:pw@broker.lan/home/tn?qos=1", Destination{Backend: "mqtt", Server: "mqtts://broker.lan", Topic: "home/tn", User: "tn", Password: "pw", Params: map[string]string{"qos": "1"}}},
+		{"pagerduty://R0UT1NG", Destination{Backend: "pagerduty", Token: "R0UT1NG"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := ParseURL(tt.url)
+			if err != nil {
+				t.Fatalf("ParseURL() returned unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseURL() =\n  %+v\nwant\n  %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseURL_Errors(t *testing.T) {
+	for _, raw := range []string{
+		"ntfy.sh/topic",
+		"pushover://user/token",
+		"gotify://host",
+		"tgram://123:abc",
+		"discord://123",
+		"mailto://me:pw@smtp.example.com",
+		"mailto://me:pw@smtp.example.com/ops@example.com",
+	} {
+		if _, err := ParseURL(raw); err == nil {
+			t.Errorf("ParseURL(%q) expected error, got nil", raw)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	d := Destination{
+		Name:     "ci",
+		URL:      "ntfys://ntfy.example.com/builds?markdown=yes",
+		Priority: "high",
+		Params:   map[string]string{"markdown": "no"},
+	}
+
+	r, err := d.Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+	if r.Name != "ci" || r.Backend != "ntfy" || r.Topic != "builds" || r.Priority != "high" {
+		t.Errorf("Resolve() = %+v", r)
+	}
+	if r.Params["markdown"] != "no" {
+		t.Errorf("params = %v, want the destination's own params to win", r.Params)
+	}
+
+	r, _ = Destination{URL: "gotify://h/tok"}.Resolve()
+	if r.Name != "gotify" {
+		t.Errorf("Name = %q, want the backend name when unset", r.Name)
+	}
+}
+
+func TestDefaultDestination_URL(t *testing.T) {
+	t.Setenv("TN_URL", "ntfy://env-topic")
+	cfg := DefaultConfig()
+	applyEnvOverrides(cfg)
+
+	d, err := cfg.DefaultDestination().Resolve()
+	if err != nil {
+		t.Fatalf("Resolve() returned unexpected error: %v", err)
+	}
+	if d.Backend != "ntfy" || d.Topic != "env-topic" || d.Server != "https://ntfy.sh" {
+		t.Errorf("destination = %+v, want one built from TN_URL", d)
+	}
+}
@@ -0,0 +1,333 @@
+// Package dbus implements just enough of the D-Bus wire protocol to call
+// methods on the session bus: EXTERNAL auth over a unix socket, message
+// framing, and marshaling of the basic types notifications need.
+package dbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message types.
+const (
+	TypeMethodCall   = 1
+	TypeMethodReturn = 2
+	TypeError        = 3
+	TypeSignal       = 4
+)
+
+// Header field codes.
+const (
+	fieldPath        = 1
+	fieldInterface   = 2
+	fieldMember      = 3
+	fieldErrorName   = 4
+	fieldReplySerial = 5
+	fieldDestination = 6
+	fieldSender      = 7
+	fieldSignature   = 8
+)
+
+// Message is a decoded D-Bus message. Body holds the arguments; see
+// decoder.value for how each type is represented.
+type Message struct {
+	Type        byte
+	Serial      uint32
+	ReplySerial uint32
+	Path        ObjectPath
+	Interface   string
+	Member      string
+	ErrorName   string
+	Destination string
+	Sender      string
+	Signature   string
+	Body        []any
+}
+
+// Error is a D-Bus error reply.
+type Error struct {
+	Name    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Name
+	}
+	return e.Name + ": " + e.Message
+}
+
+// Conn is a connection to a message bus.
+type Conn struct {
+	conn net.Conn
+	r    *bufio.Reader
+
+	mu     sync.Mutex
+	serial uint32
+	name   string
+}
+
+// SessionAddress returns the session bus address from
+// DBUS_SESSION_BUS_ADDRESS, falling back to $XDG_RUNTIME_DIR/bus.
+func SessionAddress() (string, error) {
+	if addr := os.Getenv("DBUS_SESSION_BUS_ADDRESS"); addr != "" {
+		return addr, nil
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		path := filepath.Join(dir, "bus")
+		if _, err := os.Stat(path); err == nil {
+			return "unix:path=" + path, nil
+		}
+	}
+	return "", errors.New("no D-Bus session bus found (DBUS_SESSION_BUS_ADDRESS is not set)")
+}
+
+// Dial connects to the bus at address, authenticates and registers with
+// the bus. address may list several ';'-separated unix transports. ctx
+// bounds the whole handshake, so a bus that accepts the connection but
+// never answers cannot hang the caller; it has no effect once Dial returns.
+func Dial(ctx context.Context, address string) (*Conn, error) {
+	var errs []error
+	for _, entry := range strings.Split(address, ";") {
+		if entry == "" {
+			continue
+		}
+		conn, err := dialEntry(ctx, entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c, err := handshake(ctx, conn)
+		if err != nil {
+			_ = conn.Close()
+			errs = append(errs, err)
+			continue
+		}
+		return c, nil
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("invalid D-Bus address %q", address)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// handshake authenticates and says Hello on conn, giving up when ctx is
+// done.
+func handshake(ctx context.Context, conn net.Conn) (*Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Unix(1, 0)) })
+	defer stop()
+
+	c := &Conn{conn: conn, r: bufio.NewReader(conn)}
+	err := c.auth()
+	if err == nil {
+		err = c.hello()
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			err = fmt.Errorf("%w (%w)", err, ctxErr)
+		}
+		return nil, err
+	}
+	if !stop() {
+		return nil, ctx.Err()
+	}
+	return c, conn.SetDeadline(time.Time{})
+}
+
+// dialEntry connects to a single "unix:path=..." or "unix:abstract=..."
+// address.
+func dialEntry(ctx context.Context, entry string) (net.Conn, error) {
+	transport, params, ok := strings.Cut(entry, ":")
+	if !ok || transport != "unix" {
+		return nil, fmt.Errorf("unsupported D-Bus transport in %q", entry)
+	}
+
+	kv := map[string]string{}
+	for _, p := range strings.Split(params, ",") {
+		k, v, _ := strings.Cut(p, "=")
+		if unescaped, err := url.PathUnescape(v); err == nil {
+			v = unescaped
+		}
+		kv[k] = v
+	}
+
+	var path string
+	switch {
+	case kv["path"] != "":
+		path = kv["path"]
+	case kv["abstract"] != "":
+		path = "@" + kv["abstract"]
+	default:
+		return nil, fmt.Errorf("D-Bus address %q has no path", entry)
+	}
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return dialer.DialContext(ctx, "unix", path)
+}
+
+// auth performs SASL EXTERNAL authentication with the caller's uid.
+func (c *Conn) auth() error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := c.conn.Write([]byte("\x00AUTH EXTERNAL " + uid + "\r\n")); err != nil {
+		return fmt.Errorf("D-Bus auth: %w", err)
+	}
+
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("D-Bus auth: %w", err)
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("D-Bus auth rejected: %s", strings.TrimSpace(line))
+	}
+
+	_, err = c.conn.Write([]byte("BEGIN\r\n"))
+	return err
+}
+
+// hello registers the connection with the bus and records its unique name.
+func (c *Conn) hello() error {
+	reply, err := c.Call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello")
+	if err != nil {
+		return fmt.Errorf("D-Bus Hello: %w", err)
+	}
+	if len(reply) > 0 {
+		c.name, _ = reply[0].(string)
+	}
+	return nil
+}
+
+// Name returns the unique bus name assigned to the connection.
+func (c *Conn) Name() string { return c.name }
+
+// Close closes the connection.
+func (c *Conn) Close() error { return c.conn.Close() }
+
+// SetDeadline bounds all subsequent reads and writes on the connection.
+func (c *Conn) SetDeadline(t time.Time) error { return c.conn.SetDeadline(t) }
+
+// Call invokes a method and waits for its reply, skipping any signals or
+// unrelated messages that arrive first.
+func (c *Conn) Call(dest string, path ObjectPath, iface, member string, args ...any) ([]any, error) {
+	serial, err := c.send(&Message{
+		Type:        TypeMethodCall,
+		Path:        path,
+		Interface:   iface,
+		Member:      member,
+		Destination: dest,
+	}, args)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		msg, err := c.Receive()
+		if err != nil {
+			return nil, err
+		}
+		if msg.ReplySerial != serial {
+			continue
+		}
+		if msg.Type == TypeError {
+			e := &Error{Name: msg.ErrorName}
+			if len(msg.Body) > 0 {
+				e.Message, _ = msg.Body[0].(string)
+			}
+			return nil, e
+		}
+		return msg.Body, nil
+	}
+}
+
+// RequestName asks the bus to assign a well-known name to the connection.
+func (c *Conn) RequestName(name string) error {
+	reply, err := c.Call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus",
+		"RequestName", name, uint32(0))
+	if err != nil {
+		return err
+	}
+	if len(reply) == 0 || reply[0] != uint32(1) {
+		return fmt.Errorf("could not become primary owner of %s", name)
+	}
+	return nil
+}
+
+// Return sends a method return for call with the given arguments.
+func (c *Conn) Return(call *Message, args ...any) error {
+	_, err := c.send(&Message{
+		Type:        TypeMethodReturn,
+		ReplySerial: call.Serial,
+		Destination: call.Sender,
+	}, args)
+	return err
+}
+
+// send marshals and writes msg, returning the serial it was sent with.
+func (c *Conn) send(msg *Message, args []any) (uint32, error) {
+	var body encoder
+	var sig strings.Builder
+	for _, arg := range args {
+		s, err := signatureOf(arg)
+		if err != nil {
+			return 0, err
+		}
+		sig.WriteString(s)
+		if err := body.value(s, arg); err != nil {
+			return 0, err
+		}
+	}
+	msg.Signature = sig.String()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.serial++
+	msg.Serial = c.serial
+
+	data := marshal(msg, body.buf)
+	if _, err := c.conn.Write(data); err != nil {
+		return 0, fmt.Errorf("D-Bus write: %w", err)
+	}
+	return msg.Serial, nil
+}
+
+// Receive reads the next message from the bus.
+func (c *Conn) Receive() (*Message, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(c.r, fixed); err != nil {
+		return nil, fmt.Errorf("D-Bus read: %w", err)
+	}
+
+	order, err := byteOrder(fixed[0])
+	if err != nil {
+		return nil, err
+	}
+	bodyLen := order.Uint32(fixed[4:8])
+	fieldsLen := order.Uint32(fixed[12:16])
+
+	headerLen := 16 + int(fieldsLen)
+	padded := (headerLen + 7) &^ 7
+	if fieldsLen > 1<<26 || bodyLen > 1<<27 {
+		return nil, errors.New("D-Bus message too large")
+	}
+
+	rest := make([]byte, padded-16+int(bodyLen))
+	if _, err := io.ReadFull(c.r, rest); err != nil {
+		return nil, fmt.Errorf("D-Bus read: %w", err)
+	}
+	return unmarshal(append(fixed, rest...), padded)
+}
@@ -0,0 +1,191 @@
+package dbus
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lee/term_notify/internal/dbus/dbustest"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	args := []any{
+		"app",
+		uint32(7),
+		int32(-1),
+		byte(2),
+		true,
+		[]string{"a", "bc"},
+		map[string]Variant{
+			"urgency":  {Sig: "y", Value: byte(2)},
+			"category": {Sig: "s", Value: "transfer"},
+		},
+		Variant{Sig: "u", Value: uint32(42)},
+	}
+
+	var body encoder
+	sig := ""
+	for _, a := range args {
+		s, err := signatureOf(a)
+		if err != nil {
+			t.Fatalf("signatureOf(%T) error: %v", a, err)
+		}
+		sig += s
+		if err := body.value(s, a); err != nil {
+			t.Fatalf("encoding %T: %v", a, err)
+		}
+	}
+	if sig != "suiybasa{sv}v" {
+		t.Fatalf("signature = %q, want %q", sig, "suiybasa{sv}v")
+	}
+
+	in := &Message{
+		Type:        TypeMethodCall,
+		Serial:      3,
+		Path:        "/org/example/Obj",
+		Interface:   "org.example.Iface",
+		Member:      "Do",
+		Destination: "org.example.Svc",
+		Signature:   sig,
+	}
+	data := marshal(in, body.buf)
+
+	fieldsLen := int(data[12]) | int(data[13])<<8 | int(data[14])<<16 | int(data[15])<<24
+	bodyStart := (16 + fieldsLen + 7) &^ 7
+
+	out, err := unmarshal(data, bodyStart)
+	if err != nil {
+		t.Fatalf("unmarshal() error: %v", err)
+	}
+
+	if out.Path != in.Path || out.Interface != in.Interface || out.Member != in.Member ||
+		out.Destination != in.Destination || out.Serial != in.Serial || out.Signature != sig {
+		t.Errorf("header = %+v, want %+v", out, in)
+	}
+
+	want := []any{
+		"app",
+		uint32(7),
+		int32(-1),
+		byte(2),
+		true,
+		[]string{"a", "bc"},
+		map[string]any{
+			"urgency":  Variant{Sig: "y", Value: byte(2)},
+			"category": Variant{Sig: "s", Value: "transfer"},
+		},
+		Variant{Sig: "u", Value: uint32(42)},
+	}
+	if !reflect.DeepEqual(out.Body, want) {
+		t.Errorf("body = %#v, want %#v", out.Body, want)
+	}
+}
+
+func TestCall_PrivateBus(t *testing.T) {
+	addr := dbustest.StartSession(t)
+
+	service, err := Dial(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("Dial() service error: %v", err)
+	}
+	defer service.Close() //nolint:errcheck
+	if err := service.RequestName("org.example.Echo"); err != nil {
+		t.Fatalf("RequestName() error: %v", err)
+	}
+
+	go func() {
+		for {
+			msg, err := service.Receive()
+			if err != nil {
+				return
+			}
+			if msg.Type != TypeMethodCall {
+				continue
+			}
+			if msg.Member == "Fail" {
+				_, _ = service.send(&Message{
+					Type:        TypeError,
+					ErrorName:   "org.example.Error.Boom",
+					ReplySerial: msg.Serial,
+					Destination: msg.Sender,
+				}, []any{"boom"})
+				continue
+			}
+			_ = service.Return(msg, msg.Body[0].(string)+"!", uint32(len(msg.Body)))
+		}
+	}()
+
+	client, err := Dial(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("Dial() client error: %v", err)
+	}
+	defer client.Close() //nolint:errcheck
+	_ = client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if client.Name() == "" {
+		t.Error("Name() is empty after Hello")
+	}
+
+	reply, err := client.Call("org.example.Echo", "/", "org.example.Echo", "Echo",
+		"hi", []string{"x"}, map[string]Variant{"k": {Sig: "i", Value: int32(1)}})
+	if err != nil {
+		t.Fatalf("Call() error: %v", err)
+	}
+	if !reflect.DeepEqual(reply, []any{"hi!", uint32(3)}) {
+		t.Errorf("reply = %#v, want [hi! 3]", reply)
+	}
+
+	_, err = client.Call("org.example.Echo", "/", "org.example.Echo", "Fail")
+	var dbusErr *Error
+	if !errors.As(err, &dbusErr) || dbusErr.Name != "org.example.Error.Boom" || dbusErr.Message != "boom" {
+		t.Fatalf("Call() error = %v, want org.example.Error.Boom: boom", err)
+	}
+
+	_, err = client.Call("org.example.Missing", "/", "org.example.Missing", "Nope")
+	if !errors.As(err, &dbusErr) || dbusErr.Name != "org.freedesktop.DBus.Error.ServiceUnknown" {
+		t.Errorf("Call() to missing service error = %v, want ServiceUnknown", err)
+	}
+}
+
+func TestDial_BadAddress(t *testing.T) {
+	if _, err := Dial(context.Background(), "tcp:host=localhost,port=1"); err == nil {
+		t.Error("Dial() expected error for unsupported transport, got nil")
+	}
+	if _, err := Dial(context.Background(), ""); err == nil {
+		t.Error("Dial() expected error for empty address, got nil")
+	}
+}
+
+func TestDial_UnresponsiveBus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bus")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close() //nolint:errcheck
+	go func() {
+		// Accept connections but never answer the AUTH line.
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close() //nolint:errcheck
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = Dial(ctx, "unix:path="+path)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Dial() error = %v, want the context deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Dial() took %s, want it to give up at the deadline", elapsed)
+	}
+}
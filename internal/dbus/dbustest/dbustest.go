@@ -0,0 +1,61 @@
+// Package dbustest starts private D-Bus daemons for tests.
+package dbustest
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const busConfig = `<!DOCTYPE busconfig PUBLIC "-//freedesktop//DTD D-Bus Bus Configuration 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/busconfig.dtd">
+<busconfig>
+  <type>session</type>
+  <listen>unix:dir=%DIR%</listen>
+  <auth>EXTERNAL</auth>
+  <policy context="default">
+    <allow send_destination="*" eavesdrop="true"/>
+    <allow eavesdrop="true"/>
+    <allow own="*"/>
+  </policy>
+</busconfig>
+`
+
+// StartSession runs a private dbus-daemon for the duration of the test and
+// returns its address. The test is skipped if dbus-daemon isn't installed.
+func StartSession(t testing.TB) string {
+	t.Helper()
+
+	daemon, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not found in PATH")
+	}
+
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "session.conf")
+	if err := os.WriteFile(conf, []byte(strings.ReplaceAll(busConfig, "%DIR%", dir)), 0o600); err != nil {
+		t.Fatalf("writing bus config: %v", err)
+	}
+
+	cmd := exec.Command(daemon, "--config-file="+conf, "--nofork", "--print-address=1") // #nosec G204 — test helper
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("dbus-daemon stdout: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("starting dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading dbus-daemon address: %v", err)
+	}
+	return strings.TrimSpace(addr)
+}
@@ -0,0 +1,516 @@
+package dbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ObjectPath is a D-Bus object path ("o").
+type ObjectPath string
+
+// Signature is a D-Bus type signature ("g").
+type Signature string
+
+// Variant is a value tagged with its signature ("v").
+type Variant struct {
+	Sig   string
+	Value any
+}
+
+// signatureOf returns the D-Bus signature for a supported Go value.
+func signatureOf(v any) (string, error) {
+	switch v.(type) {
+	case string:
+		return "s", nil
+	case ObjectPath:
+		return "o", nil
+	case Signature:
+		return "g", nil
+	case uint32:
+		return "u", nil
+	case int32:
+		return "i", nil
+	case byte:
+		return "y", nil
+	case bool:
+		return "b", nil
+	case Variant:
+		return "v", nil
+	case []string:
+		return "as", nil
+	case map[string]Variant:
+		return "a{sv}", nil
+	}
+	return "", fmt.Errorf("dbus: unsupported argument type %T", v)
+}
+
+// encoder appends little-endian D-Bus values to buf. Alignment is relative
+// to the start of buf, which must itself start on an 8-byte boundary of
+// the message.
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) align(n int) {
+	for len(e.buf)%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) string(s string) {
+	e.uint32(uint32(len(s))) // #nosec G115 — strings here are short
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+func (e *encoder) signature(s string) {
+	e.buf = append(e.buf, byte(len(s)))
+	e.buf = append(e.buf, s...)
+	e.buf = append(e.buf, 0)
+}
+
+// array writes an array whose elements have the given alignment, with
+// elements produced by fn.
+func (e *encoder) array(elemAlign int, fn func()) {
+	e.align(4)
+	lenPos := len(e.buf)
+	e.uint32(0)
+	e.align(elemAlign)
+	start := len(e.buf)
+	fn()
+	binary.LittleEndian.PutUint32(e.buf[lenPos:], uint32(len(e.buf)-start)) // #nosec G115
+}
+
+// value encodes v, whose signature is sig.
+func (e *encoder) value(sig string, v any) error {
+	switch sig {
+	case "s":
+		e.string(v.(string))
+	case "o":
+		e.string(string(v.(ObjectPath)))
+	case "g":
+		e.signature(string(v.(Signature)))
+	case "u":
+		e.uint32(v.(uint32))
+	case "i":
+		e.uint32(uint32(v.(int32))) // #nosec G115 — two's complement on the wire
+	case "y":
+		e.buf = append(e.buf, v.(byte))
+	case "b":
+		var b uint32
+		if v.(bool) {
+			b = 1
+		}
+		e.uint32(b)
+	case "v":
+		variant := v.(Variant)
+		e.signature(variant.Sig)
+		return e.value(variant.Sig, variant.Value)
+	case "as":
+		e.array(4, func() {
+			for _, s := range v.([]string) {
+				e.string(s)
+			}
+		})
+	case "a{sv}":
+		m := v.(map[string]Variant)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var err error
+		e.array(8, func() {
+			for _, k := range keys {
+				e.align(8)
+				e.string(k)
+				e.signature(m[k].Sig)
+				if verr := e.value(m[k].Sig, m[k].Value); verr != nil {
+					err = verr
+				}
+			}
+		})
+		return err
+	default:
+		return fmt.Errorf("dbus: cannot encode signature %q", sig)
+	}
+	return nil
+}
+
+// marshal frames a message: fixed header, header fields, padding, body.
+func marshal(msg *Message, body []byte) []byte {
+	var e encoder
+	e.buf = append(e.buf, 'l', msg.Type, 0, 1)
+	e.uint32(uint32(len(body))) // #nosec G115
+	e.uint32(msg.Serial)
+
+	type field struct {
+		code byte
+		sig  string
+		val  any
+	}
+	var fields []field
+	if msg.Path != "" {
+		fields = append(fields, field{fieldPath, "o", msg.Path})
+	}
+	if msg.Interface != "" {
+		fields = append(fields, field{fieldInterface, "s", msg.Interface})
+	}
+	if msg.Member != "" {
+		fields = append(fields, field{fieldMember, "s", msg.Member})
+	}
+	if msg.ErrorName != "" {
+		fields = append(fields, field{fieldErrorName, "s", msg.ErrorName})
+	}
+	if msg.ReplySerial != 0 {
+		fields = append(fields, field{fieldReplySerial, "u", msg.ReplySerial})
+	}
+	if msg.Destination != "" {
+		fields = append(fields, field{fieldDestination, "s", msg.Destination})
+	}
+	if msg.Signature != "" {
+		fields = append(fields, field{fieldSignature, "g", Signature(msg.Signature)})
+	}
+
+	e.array(8, func() {
+		for _, f := range fields {
+			e.align(8)
+			e.buf = append(e.buf, f.code)
+			e.signature(f.sig)
+			_ = e.value(f.sig, f.val)
+		}
+	})
+	e.align(8)
+
+	return append(e.buf, body...)
+}
+
+// decoder reads D-Bus values from a complete message.
+type decoder struct {
+	buf   []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+var errShort = errors.New("dbus: message truncated")
+
+func byteOrder(flag byte) (binary.ByteOrder, error) {
+	switch flag {
+	case 'l':
+		return binary.LittleEndian, nil
+	case 'B':
+		return binary.BigEndian, nil
+	}
+	return nil, fmt.Errorf("dbus: invalid endianness flag %q", flag)
+}
+
+func (d *decoder) align(n int) {
+	d.pos = (d.pos + n - 1) / n * n
+}
+
+func (d *decoder) need(n int) error {
+	if d.pos+n > len(d.buf) {
+		return errShort
+	}
+	return nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	d.align(4)
+	if err := d.need(4); err != nil {
+		return 0, err
+	}
+	v := d.order.Uint32(d.buf[d.pos:])
+	d.pos += 4
+	return v, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	if err := d.need(1); err != nil {
+		return 0, err
+	}
+	b := d.buf[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	if err := d.need(int(n) + 1); err != nil {
+		return "", err
+	}
+	s := string(d.buf[d.pos : d.pos+int(n)])
+	d.pos += int(n) + 1
+	return s, nil
+}
+
+func (d *decoder) signature() (string, error) {
+	n, err := d.byte()
+	if err != nil {
+		return "", err
+	}
+	if err := d.need(int(n) + 1); err != nil {
+		return "", err
+	}
+	s := string(d.buf[d.pos : d.pos+int(n)])
+	d.pos += int(n) + 1
+	return s, nil
+}
+
+// alignOf returns the alignment of the type starting with sig.
+func alignOf(sig byte) int {
+	switch sig {
+	case 'y', 'g', 'v':
+		return 1
+	case 'n', 'q':
+		return 2
+	case 'x', 't', 'd', '(', '{':
+		return 8
+	}
+	return 4
+}
+
+// value decodes the first complete type in sig and returns it with the rest
+// of the signature. Arrays decode to []string for "as", map[string]any for
+// string-keyed dicts and []any otherwise; structs decode to []any and
+// variants to Variant.
+func (d *decoder) value(sig string) (any, string, error) {
+	if sig == "" {
+		return nil, "", errors.New("dbus: empty signature")
+	}
+	rest := sig[1:]
+
+	switch sig[0] {
+	case 's':
+		v, err := d.string()
+		return v, rest, err
+	case 'o':
+		v, err := d.string()
+		return ObjectPath(v), rest, err
+	case 'g':
+		v, err := d.signature()
+		return Signature(v), rest, err
+	case 'u':
+		v, err := d.uint32()
+		return v, rest, err
+	case 'i':
+		v, err := d.uint32()
+		return int32(v), rest, err // #nosec G115 — two's complement on the wire
+	case 'b':
+		v, err := d.uint32()
+		return v != 0, rest, err
+	case 'y':
+		v, err := d.byte()
+		return v, rest, err
+	case 'n', 'q':
+		d.align(2)
+		if err := d.need(2); err != nil {
+			return nil, rest, err
+		}
+		v := d.order.Uint16(d.buf[d.pos:])
+		d.pos += 2
+		if sig[0] == 'n' {
+			return int16(v), rest, nil // #nosec G115
+		}
+		return v, rest, nil
+	case 'x', 't', 'd':
+		d.align(8)
+		if err := d.need(8); err != nil {
+			return nil, rest, err
+		}
+		v := d.order.Uint64(d.buf[d.pos:])
+		d.pos += 8
+		switch sig[0] {
+		case 'x':
+			return int64(v), rest, nil // #nosec G115
+		case 'd':
+			return math.Float64frombits(v), rest, nil
+		}
+		return v, rest, nil
+	case 'v':
+		inner, err := d.signature()
+		if err != nil {
+			return nil, rest, err
+		}
+		v, tail, err := d.value(inner)
+		if err == nil && tail != "" {
+			err = fmt.Errorf("dbus: variant signature %q is not a single type", inner)
+		}
+		return Variant{Sig: inner, Value: v}, rest, err
+	case '(':
+		d.align(8)
+		var fields []any
+		for rest != "" && rest[0] != ')' {
+			v, tail, err := d.value(rest)
+			if err != nil {
+				return nil, tail, err
+			}
+			fields = append(fields, v)
+			rest = tail
+		}
+		if rest == "" {
+			return nil, "", fmt.Errorf("dbus: unterminated struct in %q", sig)
+		}
+		return fields, rest[1:], nil
+	case 'a':
+		return d.array(rest)
+	}
+	return nil, rest, fmt.Errorf("dbus: cannot decode signature %q", sig)
+}
+
+// array decodes an array whose element signature starts elem.
+func (d *decoder) array(elem string) (any, string, error) {
+	itemSig, rest, err := skipType(elem)
+	if err != nil {
+		return nil, "", err
+	}
+
+	n, err := d.uint32()
+	if err != nil {
+		return nil, rest, err
+	}
+	d.align(alignOf(itemSig[0]))
+	end := d.pos + int(n)
+	if end > len(d.buf) {
+		return nil, rest, errShort
+	}
+
+	if itemSig[0] == '{' {
+		keySig, valSig := itemSig[1:2], itemSig[2:len(itemSig)-1]
+		if keySig != "s" {
+			return nil, rest, fmt.Errorf("dbus: unsupported dict key type in %q", itemSig)
+		}
+		m := map[string]any{}
+		for d.pos < end {
+			d.align(8)
+			k, err := d.string()
+			if err != nil {
+				return nil, rest, err
+			}
+			v, _, err := d.value(valSig)
+			if err != nil {
+				return nil, rest, err
+			}
+			m[k] = v
+		}
+		return m, rest, nil
+	}
+
+	var items []any
+	for d.pos < end {
+		v, _, err := d.value(itemSig)
+		if err != nil {
+			return nil, rest, err
+		}
+		items = append(items, v)
+	}
+
+	if itemSig == "s" {
+		strs := make([]string, len(items))
+		for i, v := range items {
+			strs[i] = v.(string)
+		}
+		return strs, rest, nil
+	}
+	return items, rest, nil
+}
+
+// skipType returns the signature following the first complete type in sig.
+func skipType(sig string) (string, string, error) {
+	if sig == "" {
+		return "", "", errors.New("dbus: empty signature")
+	}
+	switch sig[0] {
+	case 'a':
+		_, rest, err := skipType(sig[1:])
+		return sig[:len(sig)-len(rest)], rest, err
+	case '(', '{':
+		closing := byte(')')
+		if sig[0] == '{' {
+			closing = '}'
+		}
+		rest := sig[1:]
+		for rest != "" && rest[0] != closing {
+			var err error
+			if _, rest, err = skipType(rest); err != nil {
+				return "", "", err
+			}
+		}
+		if rest == "" {
+			return "", "", fmt.Errorf("dbus: unterminated container in %q", sig)
+		}
+		return sig[:len(sig)-len(rest)+1], rest[1:], nil
+	}
+	return sig[:1], sig[1:], nil
+}
+
+// unmarshal decodes a complete message whose body starts at bodyStart.
+func unmarshal(data []byte, bodyStart int) (*Message, error) {
+	order, err := byteOrder(data[0])
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{Type: data[1], Serial: order.Uint32(data[8:12])}
+
+	d := &decoder{buf: data[:bodyStart], pos: 12, order: order}
+	fieldsLen, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	end := d.pos + int(fieldsLen)
+	for d.pos < end {
+		d.align(8)
+		code, err := d.byte()
+		if err != nil {
+			return nil, err
+		}
+		variant, _, err := d.value("v")
+		if err != nil {
+			return nil, err
+		}
+		v := variant.(Variant).Value
+		switch code {
+		case fieldPath:
+			msg.Path, _ = v.(ObjectPath)
+		case fieldInterface:
+			msg.Interface, _ = v.(string)
+		case fieldMember:
+			msg.Member, _ = v.(string)
+		case fieldErrorName:
+			msg.ErrorName, _ = v.(string)
+		case fieldReplySerial:
+			msg.ReplySerial, _ = v.(uint32)
+		case fieldDestination:
+			msg.Destination, _ = v.(string)
+		case fieldSender:
+			msg.Sender, _ = v.(string)
+		case fieldSignature:
+			sig, _ := v.(Signature)
+			msg.Signature = string(sig)
+		}
+	}
+
+	body := &decoder{buf: data[bodyStart:], order: order}
+	for sig := msg.Signature; sig != ""; {
+		v, rest, err := body.value(sig)
+		if err != nil {
+			return nil, err
+		}
+		msg.Body = append(msg.Body, v)
+		sig = rest
+	}
+
+	return msg, nil
+}
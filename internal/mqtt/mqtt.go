@@ -0,0 +1,245 @@
+// Package mqtt implements a minimal MQTT 3.1.1 client: connect, publish at
+// QoS 0–2 and disconnect. It has no subscription or session support.
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Control packet types.
+const (
+	CONNECT    = 1
+	CONNACK    = 2
+	PUBLISH    = 3
+	PUBACK     = 4
+	PUBREC     = 5
+	PUBREL     = 6
+	PUBCOMP    = 7
+	DISCONNECT = 14
+)
+
+// connackErrors describes the CONNACK return codes defined by MQTT 3.1.1.
+var connackErrors = map[byte]string{
+	1: "unacceptable protocol version",
+	2: "identifier rejected",
+	3: "server unavailable",
+	4: "bad user name or password",
+	5: "not authorized",
+}
+
+// Packet is a raw control packet.
+type Packet struct {
+	Type  byte
+	Flags byte
+	Body  []byte
+}
+
+// ReadPacket reads one control packet from r.
+func ReadPacket(r io.ByteReader) (*Packet, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	// Remaining length is a base-128 varint of at most four bytes.
+	length, shift := 0, 0
+	for i := 0; ; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		length |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return nil, errors.New("mqtt: malformed remaining length")
+		}
+		shift += 7
+	}
+
+	body := make([]byte, length)
+	for i := range body {
+		if body[i], err = r.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+	return &Packet{Type: first >> 4, Flags: first & 0x0f, Body: body}, nil
+}
+
+// WritePacket writes a control packet to w.
+func WritePacket(w io.Writer, typ, flags byte, body []byte) error {
+	buf := []byte{typ<<4 | flags&0x0f}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if n == 0 {
+			break
+		}
+	}
+	_, err := w.Write(append(buf, body...))
+	return err
+}
+
+// AppendString appends an MQTT length-prefixed UTF-8 string.
+func AppendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// ReadString reads an MQTT length-prefixed string from the front of b and
+// returns it with the remaining bytes.
+func ReadString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("mqtt: truncated string")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("mqtt: truncated string")
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
+
+// Options configures a connection.
+type Options struct {
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+}
+
+// Client is a connected MQTT session. It is not safe for concurrent use.
+type Client struct {
+	conn   net.Conn
+	r      *bufio.Reader
+	nextID uint16
+}
+
+// Connect performs the MQTT handshake over an established connection. A
+// password is only sent along with a username, as MQTT 3.1.1 requires.
+func Connect(conn net.Conn, opts Options) (*Client, error) {
+	if opts.Username == "" {
+		opts.Password = ""
+	}
+	flags := byte(0x02) // clean session
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Password != "" {
+		flags |= 0x40
+	}
+
+	body := AppendString(nil, "MQTT")
+	body = append(body, 4, flags) // protocol level 4 = 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(opts.KeepAlive/time.Second))
+	body = AppendString(body, opts.ClientID)
+	if opts.Username != "" {
+		body = AppendString(body, opts.Username)
+	}
+	if opts.Password != "" {
+		body = AppendString(body, opts.Password)
+	}
+
+	c := &Client{conn: conn, r: bufio.NewReader(conn)}
+	if err := WritePacket(conn, CONNECT, 0, body); err != nil {
+		return nil, fmt.Errorf("mqtt: sending CONNECT: %w", err)
+	}
+
+	p, err := c.expect(CONNACK)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Body) != 2 {
+		return nil, errors.New("mqtt: malformed CONNACK")
+	}
+	if code := p.Body[1]; code != 0 {
+		reason, ok := connackErrors[code]
+		if !ok {
+			reason = fmt.Sprintf("return code %d", code)
+		}
+		return nil, fmt.Errorf("mqtt: connection refused: %s", reason)
+	}
+	return c, nil
+}
+
+// Publish sends payload to topic and, for QoS 1 and 2, waits until the
+// broker has acknowledged it.
+func (c *Client) Publish(topic string, payload []byte, qos byte, retain bool) error {
+	if qos > 2 {
+		return fmt.Errorf("mqtt: invalid QoS %d", qos)
+	}
+
+	flags := qos << 1
+	if retain {
+		flags |= 0x01
+	}
+
+	body := AppendString(nil, topic)
+	var id uint16
+	if qos > 0 {
+		c.nextID++
+		id = c.nextID
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, payload...)
+
+	if err := WritePacket(c.conn, PUBLISH, flags, body); err != nil {
+		return fmt.Errorf("mqtt: sending PUBLISH: %w", err)
+	}
+
+	switch qos {
+	case 1:
+		return c.expectAck(PUBACK, id)
+	case 2:
+		if err := c.expectAck(PUBREC, id); err != nil {
+			return err
+		}
+		if err := WritePacket(c.conn, PUBREL, 0x02, binary.BigEndian.AppendUint16(nil, id)); err != nil {
+			return fmt.Errorf("mqtt: sending PUBREL: %w", err)
+		}
+		return c.expectAck(PUBCOMP, id)
+	}
+	return nil
+}
+
+// Disconnect ends the session cleanly and closes the connection.
+func (c *Client) Disconnect() error {
+	err := WritePacket(c.conn, DISCONNECT, 0, nil)
+	if cerr := c.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (c *Client) expect(typ byte) (*Packet, error) {
+	p, err := ReadPacket(c.r)
+	if err != nil {
+		return nil, fmt.Errorf("mqtt: reading reply: %w", err)
+	}
+	if p.Type != typ {
+		return nil, fmt.Errorf("mqtt: expected packet type %d, got %d", typ, p.Type)
+	}
+	return p, nil
+}
+
+func (c *Client) expectAck(typ byte, id uint16) error {
+	p, err := c.expect(typ)
+	if err != nil {
+		return err
+	}
+	if len(p.Body) < 2 || binary.BigEndian.Uint16(p.Body) != id {
+		return fmt.Errorf("mqtt: acknowledgement for wrong packet ID")
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+)
+
+func TestPacketRoundTrip(t *testing.T) {
+	// Sizes straddle each boundary of the remaining-length varint.
+	for _, size := range []int{0, 127, 128, 16383, 16384, 2097152} {
+		body := bytes.Repeat([]byte{0xab}, size)
+
+		var buf bytes.Buffer
+		if err := WritePacket(&buf, PUBLISH, 0x0b, body); err != nil {
+			t.Fatalf("WritePacket(%d) returned unexpected error: %v", size, err)
+		}
+
+		p, err := ReadPacket(bufio.NewReader(&buf))
+		if err != nil {
+			t.Fatalf("ReadPacket(%d) returned unexpected error: %v", size, err)
+		}
+		if p.Type != PUBLISH || p.Flags != 0x0b || !bytes.Equal(p.Body, body) {
+			t.Errorf("size %d: got type %d flags %#x len %d", size, p.Type, p.Flags, len(p.Body))
+		}
+	}
+}
+
+func TestReadPacket_MalformedLength(t *testing.T) {
+	r := bufio.NewReader(bytes.NewReader([]byte{0x30, 0xff, 0xff, 0xff, 0xff, 0x01}))
+	if _, err := ReadPacket(r); err == nil {
+		t.Error("ReadPacket() expected error for a five-byte remaining length, got nil")
+	}
+}
+
+func TestString(t *testing.T) {
+	b := AppendString(nil, "home/tn")
+	if !bytes.Equal(b[:2], []byte{0, 7}) {
+		t.Errorf("length prefix = %v, want [0 7]", b[:2])
+	}
+
+	s, rest, err := ReadString(append(b, 'x'))
+	if err != nil || s != "home/tn" || string(rest) != "x" {
+		t.Errorf("ReadString() = %q, %q, %v", s, rest, err)
+	}
+	if _, _, err := ReadString([]byte{0, 9, 'a'}); err == nil {
+		t.Error("ReadString() expected error for truncated string, got nil")
+	}
+}
+
+func TestConnect_Credentials(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		flags byte
+	}{
+		{"none", Options{}, 0x02},
+		{"user and password", Options{Username: "tn", Password: "pw"}, 0xc2},
+		{"user only", Options{Username: "tn"}, 0x82},
+		{"password only is not sent", Options{Password: "pw"}, 0x02},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, broker := net.Pipe()
+			defer client.Close() //nolint:errcheck
+
+			connect := make(chan *Packet, 1)
+			go func() {
+				defer broker.Close() //nolint:errcheck
+				p, err := ReadPacket(bufio.NewReader(broker))
+				if err != nil {
+					close(connect)
+					return
+				}
+				connect <- p
+				_ = WritePacket(broker, CONNACK, 0, []byte{0, 0})
+			}()
+
+			if _, err := Connect(client, tt.opts); err != nil {
+				t.Fatalf("Connect() returned unexpected error: %v", err)
+			}
+			p := <-connect
+			if p == nil || len(p.Body) < 8 {
+				t.Fatal("broker did not receive a CONNECT packet")
+			}
+			if got := p.Body[7]; got != tt.flags {
+				t.Errorf("connect flags = %#x, want %#x", got, tt.flags)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Package mqtttest runs an in-process MQTT broker stand-in for tests.
+package mqtttest
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/lee/term_notify/internal/mqtt"
+)
+
+// Message is a PUBLISH received by the broker.
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// Broker accepts connections, acknowledges CONNECT and PUBLISH packets and
+// records what it received.
+type Broker struct {
+	Addr string
+
+	mu       sync.Mutex
+	code     byte
+	clientID string
+	username string
+	password string
+	messages []Message
+	done     chan struct{}
+}
+
+// Start runs a broker on a loopback port until the test ends. If tlsConfig
+// is non-nil the broker only accepts TLS connections.
+func Start(t testing.TB, tlsConfig *tls.Config) *Broker {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	b := &Broker{Addr: ln.Addr().String(), done: make(chan struct{}, 16)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+// Refuse makes later connections fail with the given CONNACK return code.
+func (b *Broker) Refuse(code byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.code = code
+}
+
+// Credentials returns the client ID, user name and password of the most
+// recent CONNECT.
+func (b *Broker) Credentials() (clientID, username, password string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.clientID, b.username, b.password
+}
+
+// Messages returns every PUBLISH received so far.
+func (b *Broker) Messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message(nil), b.messages...)
+}
+
+// Done is signalled each time a client session ends.
+func (b *Broker) Done() <-chan struct{} { return b.done }
+
+func (b *Broker) serve(conn net.Conn) {
+	defer func() { b.done <- struct{}{} }()
+	defer conn.Close() //nolint:errcheck
+
+	r := bufio.NewReader(conn)
+	for {
+		p, err := mqtt.ReadPacket(r)
+		if err != nil {
+			return
+		}
+
+		switch p.Type {
+		case mqtt.CONNECT:
+			code := b.recordConnect(p)
+			_ = mqtt.WritePacket(conn, mqtt.CONNACK, 0, []byte{0, code})
+			if code != 0 {
+				return
+			}
+		case mqtt.PUBLISH:
+			id := b.recordPublish(p)
+			switch qos := (p.Flags >> 1) & 0x03; qos {
+			case 1:
+				_ = mqtt.WritePacket(conn, mqtt.PUBACK, 0, id)
+			case 2:
+				_ = mqtt.WritePacket(conn, mqtt.PUBREC, 0, id)
+			}
+		case mqtt.PUBREL:
+			_ = mqtt.WritePacket(conn, mqtt.PUBCOMP, 0, p.Body)
+		case mqtt.DISCONNECT:
+			return
+		}
+	}
+}
+
+// recordConnect stores the client's credentials and returns the CONNACK
+// return code to answer with.
+func (b *Broker) recordConnect(p *mqtt.Packet) byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, rest, _ := mqtt.ReadString(p.Body) // protocol name
+	if len(rest) < 4 {
+		return 1
+	}
+	flags := rest[1]
+	rest = rest[4:]
+
+	b.clientID, rest, _ = mqtt.ReadString(rest)
+	if flags&0x80 != 0 {
+		b.username, rest, _ = mqtt.ReadString(rest)
+	}
+	if flags&0x40 != 0 {
+		b.password, _, _ = mqtt.ReadString(rest)
+	}
+	return b.code
+}
+
+// recordPublish stores the message and returns its encoded packet ID.
+func (b *Broker) recordPublish(p *mqtt.Packet) []byte {
+	topic, rest, _ := mqtt.ReadString(p.Body)
+	qos := (p.Flags >> 1) & 0x03
+
+	var id []byte
+	if qos > 0 && len(rest) >= 2 {
+		id = binary.BigEndian.AppendUint16(nil, binary.BigEndian.Uint16(rest))
+		rest = rest[2:]
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, Message{
+		Topic:   topic,
+		Payload: append([]byte(nil), rest...),
+		QoS:     qos,
+		Retain:  p.Flags&0x01 != 0,
+	})
+	return id
+}
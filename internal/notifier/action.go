@@ -0,0 +1,131 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Action is a button shown with a notification, modelled on ntfy's action
+// buttons: "view" opens URL, "http" sends a request to URL, and
+// "broadcast" sends an Android broadcast intent.
+type Action struct {
+	Action  string            `json:"action"`
+	Label   string            `json:"label"`
+	URL     string            `json:"url,omitempty"`
+	Clear   bool              `json:"clear,omitempty"`
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	Intent  string            `json:"intent,omitempty"`
+	Extras  map[string]string `json:"extras,omitempty"`
+}
+
+// ParseAction reads an action in ntfy's short format, e.g.
+//
+//	view, Open logs, https://ci.example.com/job/42
+//	http, Restart, https://api.example.com/restart, method=PUT, headers.Authorization=Bearer x, clear=true
+//	broadcast, Take picture, extras.cmd=pic
+//
+// Values containing commas can be quoted with single or double quotes.
+func ParseAction(s string) (Action, error) {
+	fields, err := splitAction(s)
+	if err != nil {
+		return Action{}, err
+	}
+	if len(fields) < 2 {
+		return Action{}, fmt.Errorf("invalid action %q: want <action>, <label>[, <url>][, key=value...]", s)
+	}
+
+	a := Action{Action: strings.ToLower(fields[0]), Label: fields[1]}
+	switch a.Action {
+	case "view", "http", "broadcast":
+	default:
+		return Action{}, fmt.Errorf("invalid action %q: type must be view, http or broadcast", s)
+	}
+
+	for i, field := range fields[2:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || !isActionKey(key) {
+			// The first free-standing value is the URL.
+			if i == 0 && a.Action != "broadcast" {
+				a.URL = field
+				continue
+			}
+			return Action{}, fmt.Errorf("invalid action %q: unknown parameter %q", s, field)
+		}
+
+		switch key = strings.TrimSpace(key); {
+		case key == "url":
+			a.URL = value
+		case key == "clear":
+			a.Clear = value == "true" || value == "yes" || value == "1"
+		case key == "method":
+			a.Method = value
+		case key == "body":
+			a.Body = value
+		case key == "intent":
+			a.Intent = value
+		case strings.HasPrefix(key, "headers."):
+			if a.Headers == nil {
+				a.Headers = map[string]string{}
+			}
+			a.Headers[strings.TrimPrefix(key, "headers.")] = value
+		case strings.HasPrefix(key, "extras."):
+			if a.Extras == nil {
+				a.Extras = map[string]string{}
+			}
+			a.Extras[strings.TrimPrefix(key, "extras.")] = value
+		}
+	}
+
+	if a.Action != "broadcast" && a.URL == "" {
+		return Action{}, fmt.Errorf("invalid action %q: %s actions need a URL", s, a.Action)
+	}
+	return a, nil
+}
+
+// isActionKey reports whether key is one of the action parameters, so that
+// a URL containing '=' is not mistaken for one.
+func isActionKey(key string) bool {
+	switch key = strings.TrimSpace(key); key {
+	case "url", "clear", "method", "body", "intent":
+		return true
+	}
+	return strings.HasPrefix(key, "headers.") || strings.HasPrefix(key, "extras.")
+}
+
+// splitAction splits s on commas and trims each field. A field or key=value
+// value that starts with a quote runs to the matching quote, commas
+// included.
+func splitAction(s string) ([]string, error) {
+	var fields []string
+	var cur strings.Builder
+	var quote rune
+
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case (r == '"' || r == '\'') && quotable(cur.String()):
+			quote = r
+		case r == ',':
+			fields = append(fields, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("invalid action %q: unterminated quote", s)
+	}
+	return append(fields, strings.TrimSpace(cur.String())), nil
+}
+
+// quotable reports whether a quote following field would open a quoted
+// value: it must start the field or directly follow '='.
+func quotable(field string) bool {
+	field = strings.TrimSpace(field)
+	return field == "" || strings.HasSuffix(field, "=")
+}
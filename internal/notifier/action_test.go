@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Action
+	}{
+		{
+			"view, Open logs, https://ci.example.com/job/42",
+			Action{Action: "view", Label: "Open logs", URL: "https://ci.example.com/job/42"},
+		},
+		{
+			"view, Dashboard, https://grafana.local/d/x?from=now-1h&to=now, clear=true",
+			Action{Action: "view", Label: "Dashboard", URL: "https://grafana.local/d/x?from=now-1h&to=now", Clear: true},
+		},
+		{
+			`http, Restart, https://api.local/restart, method=PUT, headers.Authorization=Bearer x, body='{"a": 1, "b": 2}'`,
+			Action{Action: "http", Label: "Restart", URL: "https://api.local/restart", Method: "PUT",
+				Headers: map[string]string{"Authorization": "Bearer x"}, Body: `{"a": 1, "b": 2}`},
+		},
+		{
+			"broadcast, Take picture, extras.cmd=pic, extras.camera=front",
+			Action{Action: "broadcast", Label: "Take picture", Extras: map[string]string{"cmd": "pic", "camera": "front"}},
+		},
+		{
+			`VIEW, "Logs, full", url=https://x.local, clear=yes`,
+			Action{Action: "view", Label: "Logs, full", URL: "https://x.local", Clear: true},
+		},
+		{
+			"view, Don't panic, https://x.local",
+			Action{Action: "view", Label: "Don't panic", URL: "https://x.local"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseAction(tt.in)
+			if err != nil {
+				t.Fatalf("ParseAction() returned unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAction() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAction_Errors(t *testing.T) {
+	for _, in := range []string{
+		"view",
+		"open, Logs, https://x.local",
+		"view, Logs",
+		"http, Go, https://x.local, sideways=1",
+		`view, "Logs, https://x.local`,
+	} {
+		if _, err := ParseAction(in); err == nil {
+			t.Errorf("ParseAction(%q) expected error, got nil", in)
+		}
+	}
+}
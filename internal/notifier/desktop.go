@@ -0,0 +1,130 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lee/term_notify/internal/dbus"
+)
+
+func init() {
+	Register("desktop", func(opts Options) (Notifier, error) {
+		return &desktop{address: opts.Params["address"]}, nil
+	})
+}
+
+// Freedesktop notification urgency levels.
+const (
+	urgencyLow      byte = 0
+	urgencyNormal   byte = 1
+	urgencyCritical byte = 2
+)
+
+// desktop shows a native popup through the org.freedesktop.Notifications
+// service on the D-Bus session bus.
+type desktop struct {
+	address string // session bus address; empty means auto-detect
+}
+
+func (d *desktop) Name() string { return "desktop" }
+
+func (d *desktop) Validate() error {
+	if d.address != "" {
+		return nil
+	}
+	_, err := dbus.SessionAddress()
+	return err
+}
+
+func (d *desktop) Send(ctx context.Context, msg *Message) error {
+	address := d.address
+	if address == "" {
+		var err error
+		if address, err = dbus.SessionAddress(); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+	}
+	conn, err := dbus.Dial(ctx, address)
+	if err != nil {
+		return fmt.Errorf("connecting to session bus: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	body := msg.Body
+	if desktopMarkup(conn) {
+		body = markupEscaper.Replace(body)
+	}
+
+	icon, hints := desktopStyle(msg)
+	_, err = conn.Call(
+		"org.freedesktop.Notifications",
+		"/org/freedesktop/Notifications",
+		"org.freedesktop.Notifications",
+		"Notify",
+		"term_notify", // app_name
+		uint32(0),     // replaces_id
+		icon,
+		msg.Title,
+		body,
+		[]string{}, // actions
+		hints,
+		int32(-1), // expire_timeout: server default
+	)
+	if err != nil {
+		return fmt.Errorf("sending desktop notification: %w", err)
+	}
+	return nil
+}
+
+// markupEscaper escapes the characters that notification servers reading
+// the body as markup would otherwise take for tags or entities.
+var markupEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// desktopMarkup reports whether the notification server reads the body as
+// markup. Servers that do not would show escaped text literally.
+func desktopMarkup(conn *dbus.Conn) bool {
+	reply, err := conn.Call(
+		"org.freedesktop.Notifications",
+		"/org/freedesktop/Notifications",
+		"org.freedesktop.Notifications",
+		"GetCapabilities",
+	)
+	if err != nil || len(reply) == 0 {
+		return false
+	}
+	caps, _ := reply[0].([]string)
+	return slices.Contains(caps, "body-markup")
+}
+
+// desktopStyle picks the icon and hints for msg: an urgency mapped from the
+// ntfy-style priority, and a success or failure icon for command results.
+func desktopStyle(msg *Message) (string, map[string]dbus.Variant) {
+	urgency := urgencyNormal
+	switch msg.Priority {
+	case "min", "low", "1", "2":
+		urgency = urgencyLow
+	case "max", "urgent", "5":
+		urgency = urgencyCritical
+	}
+
+	icon := "dialog-information"
+	if run := msg.Run; run != nil && !run.Succeeded() {
+		icon = "dialog-error"
+	}
+
+	return icon, map[string]dbus.Variant{
+		"urgency": {Sig: "y", Value: urgency},
+	}
+}
@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lee/term_notify/internal/dbus"
+	"github.com/lee/term_notify/internal/dbus/dbustest"
+)
+
+// fakeNotificationServer claims org.freedesktop.Notifications on the bus at
+// addr, advertising caps, and delivers each Notify call it receives on the
+// returned channel.
+func fakeNotificationServer(t *testing.T, addr string, caps ...string) <-chan *dbus.Message {
+	t.Helper()
+
+	conn, err := dbus.Dial(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	if err := conn.RequestName("org.freedesktop.Notifications"); err != nil {
+		t.Fatalf("RequestName() error: %v", err)
+	}
+
+	calls := make(chan *dbus.Message, 1)
+	go func() {
+		for {
+			msg, err := conn.Receive()
+			if err != nil {
+				return
+			}
+			if msg.Type == dbus.TypeMethodCall && msg.Member == "GetCapabilities" {
+				_ = conn.Return(msg, append([]string{}, caps...))
+			}
+			if msg.Type == dbus.TypeMethodCall && msg.Member == "Notify" {
+				_ = conn.Return(msg, uint32(1))
+				calls <- msg
+			}
+		}
+	}()
+	return calls
+}
+
+func TestDesktop_Send(t *testing.T) {
+	addr := dbustest.StartSession(t)
+	calls := fakeNotificationServer(t, addr)
+
+	n, err := New("desktop", Options{Params: map[string]string{"address": addr}})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := n.Validate(); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title:    "❌ Command Failed",
+		Body:     "make\nFailed in 1.0s (exit code 2)",
+		Priority: "max",
+		Run:      &RunInfo{Command: "make", ExitCode: 2, Duration: time.Second},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	var call *dbus.Message
+	select {
+	case call = <-calls:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification server received no Notify call")
+	}
+
+	if call.Interface != "org.freedesktop.Notifications" || call.Signature != "susssasa{sv}i" {
+		t.Fatalf("call = %s.%s(%s), want org.freedesktop.Notifications.Notify(susssasa{sv}i)",
+			call.Interface, call.Member, call.Signature)
+	}
+	if call.Body[0] != "term_notify" {
+		t.Errorf("app_name = %v, want term_notify", call.Body[0])
+	}
+	if call.Body[2] != "dialog-error" {
+		t.Errorf("app_icon = %v, want dialog-error for a failed run", call.Body[2])
+	}
+	if call.Body[3] != msg.Title || call.Body[4] != msg.Body {
+		t.Errorf("summary/body = %v/%v, want title/body", call.Body[3], call.Body[4])
+	}
+	hints := call.Body[6].(map[string]any)
+	if got := hints["urgency"]; got != (dbus.Variant{Sig: "y", Value: urgencyCritical}) {
+		t.Errorf("urgency hint = %v, want critical", got)
+	}
+}
+
+func TestDesktop_BodyMarkup(t *testing.T) {
+	msg := &Message{Title: "✅ Command Succeeded", Body: "make 2>&1 | tee <log>"}
+	tests := []struct {
+		caps []string
+		want string
+	}{
+		{nil, msg.Body},
+		{[]string{"body", "body-markup"}, "make 2&gt;&amp;1 | tee &lt;log&gt;"},
+	}
+	for _, tt := range tests {
+		addr := dbustest.StartSession(t)
+		calls := fakeNotificationServer(t, addr, tt.caps...)
+
+		n := &desktop{address: addr}
+		if err := n.Send(context.Background(), msg); err != nil {
+			t.Fatalf("Send() returned unexpected error: %v", err)
+		}
+		select {
+		case call := <-calls:
+			if call.Body[4] != tt.want {
+				t.Errorf("caps %v: body = %q, want %q", tt.caps, call.Body[4], tt.want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("notification server received no Notify call")
+		}
+	}
+}
+
+func TestDesktop_NoServer(t *testing.T) {
+	addr := dbustest.StartSession(t)
+
+	n := &desktop{address: addr}
+	if err := n.Send(context.Background(), &Message{Title: "t"}); err == nil {
+		t.Fatal("Send() expected error when no notification server is running, got nil")
+	}
+}
+
+func TestDesktopStyle(t *testing.T) {
+	tests := []struct {
+		name        string
+		msg         *Message
+		wantIcon    string
+		wantUrgency byte
+	}{
+		{"plain default", &Message{Priority: "default"}, "dialog-information", urgencyNormal},
+		{"low priority", &Message{Priority: "low"}, "dialog-information", urgencyLow},
+		{"high priority", &Message{Priority: "high"}, "dialog-information", urgencyNormal},
+		{"urgent", &Message{Priority: "urgent"}, "dialog-information", urgencyCritical},
+		{"succeeded run", &Message{Run: &RunInfo{}}, "dialog-information", urgencyNormal},
+		{"failed run", &Message{Run: &RunInfo{ExitCode: 1}}, "dialog-error", urgencyNormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			icon, hints := desktopStyle(tt.msg)
+			if icon != tt.wantIcon {
+				t.Errorf("icon = %q, want %q", icon, tt.wantIcon)
+			}
+			if got := hints["urgency"].Value; got != tt.wantUrgency {
+				t.Errorf("urgency = %v, want %v", got, tt.wantUrgency)
+			}
+		})
+	}
+}
+
+func TestDesktop_ValidateWithoutBus(t *testing.T) {
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	if err := (&desktop{}).Validate(); err == nil {
+		t.Error("Validate() expected error without a session bus, got nil")
+	}
+}
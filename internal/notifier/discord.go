@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+func init() {
+	Register("discord", func(opts Options) (Notifier, error) {
+		return &discord{webhook: opts.Server}, nil
+	})
+}
+
+// discord posts messages to a Discord channel webhook as embeds.
+type discord struct {
+	webhook string
+}
+
+type discordPayload struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color,omitempty"`
+	Fields      []discordField `json:"fields,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+func (d *discord) Name() string { return "discord" }
+
+// String returns the webhook host; the full URL embeds a secret.
+func (d *discord) String() string {
+	if u, err := url.Parse(d.webhook); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "discord"
+}
+
+func (d *discord) Validate() error {
+	if d.webhook == "" {
+		return fmt.Errorf("discord webhook URL is required — set it as the server")
+	}
+	return nil
+}
+
+// Send posts the embed. Discord's 429 responses give the wait in the body,
+// to a finer resolution than Retry-After; it is copied onto the error for
+// the retry policy to honor.
+func (d *discord) Send(ctx context.Context, msg *Message) error {
+	_, err := doJSON(ctx, "discord", "POST", d.webhook, nil, discordMessage(msg))
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		httpErr.RetryAfter = discordRetryAfter(httpErr)
+	}
+	return err
+}
+
+// discordRetryAfter reads the rate-limit wait from a 429 response, preferring
+// the JSON body's fractional retry_after over the Retry-After header.
+func discordRetryAfter(e *HTTPError) time.Duration {
+	var body struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if json.Unmarshal([]byte(e.Body), &body) == nil && body.RetryAfter > 0 {
+		return time.Duration(body.RetryAfter * float64(time.Second))
+	}
+	if secs, err := strconv.ParseFloat(e.Header.Get("Retry-After"), 64); err == nil {
+		return time.Duration(secs * float64(time.Second))
+	}
+	return e.RetryAfter
+}
+
+// Discord's embed limits, in characters. A longer value gets the whole
+// message rejected.
+const (
+	discordMaxTitle       = 256
+	discordMaxDescription = 4096
+	discordMaxFieldValue  = 1024
+)
+
+// discordMessage renders msg as a single embed. Command results get a
+// green or red color and one field per detail.
+func discordMessage(msg *Message) *discordPayload {
+	embed := discordEmbed{
+		Title:       truncate(msg.Title, discordMaxTitle),
+		Description: truncate(msg.Body, discordMaxDescription),
+	}
+
+	if run := msg.Run; run != nil {
+		embed.Description = ""
+		embed.Fields = []discordField{
+			{Name: "Command", Value: "`" + truncate(run.Command, discordMaxFieldValue-2) + "`"},
+			{Name: "Duration", Value: FormatDuration(run.Duration), Inline: true},
+			{Name: "Exit code", Value: strconv.Itoa(run.ExitCode), Inline: true},
+		}
+		if run.Host != "" {
+			embed.Fields = append(embed.Fields, discordField{Name: "Host", Value: truncate(run.Host, discordMaxFieldValue), Inline: true})
+		}
+		embed.Color = colorSuccess
+		if !run.Succeeded() {
+			embed.Color = colorFailure
+		}
+	}
+
+	return &discordPayload{Embeds: []discordEmbed{embed}}
+}
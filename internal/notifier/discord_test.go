@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestDiscord_SendRunResult(t *testing.T) {
+	var payload discordPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n, err := New("discord", Options{Server: server.URL + "/api/webhooks/1/abc"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title: "✅ Command Succeeded",
+		Run:   &RunInfo{Command: "go test ./...", Duration: 90 * time.Second, Host: "devbox"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if len(payload.Embeds) != 1 {
+		t.Fatalf("len(embeds) = %d, want 1", len(payload.Embeds))
+	}
+	e := payload.Embeds[0]
+	if e.Title != "✅ Command Succeeded" {
+		t.Errorf("title = %q, want the message title", e.Title)
+	}
+	if e.Color != colorSuccess {
+		t.Errorf("color = %#x, want %#x", e.Color, colorSuccess)
+	}
+
+	want := map[string]string{
+		"Command":   "`go test ./...`",
+		"Duration":  "1m 30s",
+		"Exit code": "0",
+		"Host":      "devbox",
+	}
+	if len(e.Fields) != len(want) {
+		t.Fatalf("fields = %+v, want %d fields", e.Fields, len(want))
+	}
+	for _, f := range e.Fields {
+		if want[f.Name] != f.Value {
+			t.Errorf("field %q = %q, want %q", f.Name, f.Value, want[f.Name])
+		}
+	}
+}
+
+func TestDiscord_FailureColor(t *testing.T) {
+	p := discordMessage(&Message{Run: &RunInfo{Command: "false", ExitCode: 1}})
+	if got := p.Embeds[0].Color; got != colorFailure {
+		t.Errorf("color = %#x, want %#x", got, colorFailure)
+	}
+}
+
+func TestDiscord_Limits(t *testing.T) {
+	long := strings.Repeat("x", 5000)
+	e := discordMessage(&Message{Title: long, Run: &RunInfo{Command: long}}).Embeds[0]
+	if n := utf8.RuneCountInString(e.Title); n != discordMaxTitle {
+		t.Errorf("title has %d characters, want %d", n, discordMaxTitle)
+	}
+	if n := utf8.RuneCountInString(e.Fields[0].Value); n != discordMaxFieldValue {
+		t.Errorf("command field has %d characters, want %d", n, discordMaxFieldValue)
+	}
+
+	e = discordMessage(&Message{Body: long}).Embeds[0]
+	if n := utf8.RuneCountInString(e.Description); n != discordMaxDescription {
+		t.Errorf("description has %d characters, want %d", n, discordMaxDescription)
+	}
+}
+
+func TestDiscord_RateLimitRetry(t *testing.T) {
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message":"You are being rate limited.","retry_after":0.01,"global":false}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := &discord{webhook: server.URL}
+	attempts, err := DefaultRetry.send(context.Background(), n, &Message{Title: "t"})
+	if err != nil {
+		t.Fatalf("send() returned unexpected error: %v", err)
+	}
+	if calls != 2 || attempts != 2 {
+		t.Errorf("server received %d requests in %d attempts, want 2", calls, attempts)
+	}
+}
+
+func TestDiscord_RateLimitRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		body   string
+		want   time.Duration
+	}{
+		{"body", "1", `{"retry_after":0.25}`, 250 * time.Millisecond},
+		{"fractional header", "1.5", ``, 1500 * time.Millisecond},
+		{"none", "", `{}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if tt.header != "" {
+					w.Header().Set("Retry-After", tt.header)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			n := &discord{webhook: server.URL}
+			err := n.Send(context.Background(), &Message{Title: "t"})
+
+			// Retrying is left to the dispatcher's policy.
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) || calls != 1 {
+				t.Fatalf("Send() = %v after %d requests, want one 429", err, calls)
+			}
+			if httpErr.RetryAfter != tt.want {
+				t.Errorf("RetryAfter = %s, want %s", httpErr.RetryAfter, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscord_RateLimitTooLong(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"retry_after":3600}`))
+	}))
+	defer server.Close()
+
+	n := &discord{webhook: server.URL}
+	if _, err := DefaultRetry.send(context.Background(), n, &Message{Title: "t"}); err == nil {
+		t.Fatal("send() expected error when retry_after exceeds the deadline, got nil")
+	}
+	if calls != 1 {
+		t.Errorf("server received %d requests, want 1", calls)
+	}
+}
@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Destination pairs a Notifier with the name it was configured under.
+type Destination struct {
+	Name     string
+	Priority string // overrides Message.Priority when set
+	Notifier Notifier
+	Retry    RetryPolicy
+}
+
+// Result reports the outcome of delivering to one Destination.
+type Result struct {
+	Destination string
+	Backend     string
+	Err         error
+	// Attempts is how many times delivery was tried.
+	Attempts int
+}
+
+// Recorder is implemented by backends that log the outcome of a dispatch,
+// such as an audit file, rather than only delivering the message.
+type Recorder interface {
+	Notifier
+	// Record is called with the results of every non-recording
+	// destination once they have all finished.
+	Record(ctx context.Context, msg *Message, results []Result) error
+}
+
+// Dispatch delivers msg to every destination concurrently, retrying each as
+// its RetryPolicy allows, and waits for all of them. Recorders run
+// afterwards, once, with the other destinations' results.
+// Results are returned in the same order as dests.
+func Dispatch(ctx context.Context, dests []Destination, msg *Message) []Result {
+	results := make([]Result, len(dests))
+	var recorders []int
+
+	var wg sync.WaitGroup
+	for i, d := range dests {
+		results[i] = Result{Destination: d.Name, Backend: d.Notifier.Name()}
+		if _, ok := d.Notifier.(Recorder); ok {
+			recorders = append(recorders, i)
+			continue
+		}
+
+		m := withPriority(msg, d.Priority)
+		if err := checkDelay(d.Notifier, m); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].Attempts, results[i].Err = d.Retry.send(ctx, d.Notifier, m)
+		}()
+	}
+	wg.Wait()
+
+	if len(recorders) == 0 {
+		return results
+	}
+
+	delivered := make([]Result, 0, len(dests)-len(recorders))
+	for i, r := range results {
+		if _, ok := dests[i].Notifier.(Recorder); !ok {
+			delivered = append(delivered, r)
+		}
+	}
+	for _, i := range recorders {
+		d := dests[i]
+		results[i].Attempts = 1
+		results[i].Err = d.Notifier.(Recorder).Record(ctx, withPriority(msg, d.Priority), delivered)
+	}
+
+	return results
+}
+
+// checkDelay reports an error if msg is scheduled for later but n cannot
+// hold it, rather than letting n deliver it straight away.
+func checkDelay(n Notifier, msg *Message) error {
+	if msg.Delay <= 0 {
+		return nil
+	}
+	s, ok := n.(Scheduler)
+	if !ok {
+		return fmt.Errorf("%s does not support scheduled delivery", n.Name())
+	}
+	if limit := s.MaxDelay(); msg.Delay > limit {
+		return fmt.Errorf("%s can schedule at most %s ahead", n.Name(), FormatDuration(limit))
+	}
+	return nil
+}
+
+// withPriority returns a copy of msg with its priority overridden, if set.
+func withPriority(msg *Message, priority string) *Message {
+	m := *msg
+	if priority != "" {
+		m.Priority = priority
+	}
+	return &m
+}
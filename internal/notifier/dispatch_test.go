@@ -0,0 +1,112 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeNotifier struct {
+	name  string
+	err   error
+	delay time.Duration
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+func (f *fakeNotifier) Name() string    { return f.name }
+func (f *fakeNotifier) Validate() error { return nil }
+
+func (f *fakeNotifier) Send(ctx context.Context, msg *Message) error {
+	time.Sleep(f.delay)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, *msg)
+	return f.err
+}
+
+func TestDispatch_ResultsInOrder(t *testing.T) {
+	slow := &fakeNotifier{name: "slow", delay: 20 * time.Millisecond}
+	failing := &fakeNotifier{name: "failing", err: errors.New("boom")}
+	fast := &fakeNotifier{name: "fast"}
+
+	dests := []Destination{
+		{Name: "phone", Notifier: slow},
+		{Name: "chat", Notifier: failing},
+		{Name: "log", Notifier: fast},
+	}
+
+	results := Dispatch(context.Background(), dests, &Message{Title: "t", Body: "b"})
+
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+	wantNames := []string{"phone", "chat", "log"}
+	for i, r := range results {
+		if r.Destination != wantNames[i] {
+			t.Errorf("results[%d].Destination = %q, want %q", i, r.Destination, wantNames[i])
+		}
+	}
+	if results[0].Err != nil || results[2].Err != nil {
+		t.Errorf("unexpected errors: %v, %v", results[0].Err, results[2].Err)
+	}
+	if results[1].Err == nil || results[1].Backend != "failing" {
+		t.Errorf("results[1] = %+v, want error from backend %q", results[1], "failing")
+	}
+	for _, f := range []*fakeNotifier{slow, failing, fast} {
+		if len(f.sent) != 1 {
+			t.Errorf("%s received %d messages, want 1", f.name, len(f.sent))
+		}
+	}
+}
+
+func TestDispatch_PriorityOverride(t *testing.T) {
+	loud := &fakeNotifier{name: "loud"}
+	quiet := &fakeNotifier{name: "quiet"}
+
+	msg := &Message{Priority: "default"}
+	Dispatch(context.Background(), []Destination{
+		{Name: "loud", Priority: "max", Notifier: loud},
+		{Name: "quiet", Notifier: quiet},
+	}, msg)
+
+	if got := loud.sent[0].Priority; got != "max" {
+		t.Errorf("overridden priority = %q, want %q", got, "max")
+	}
+	if got := quiet.sent[0].Priority; got != "default" {
+		t.Errorf("inherited priority = %q, want %q", got, "default")
+	}
+	if msg.Priority != "default" {
+		t.Errorf("Dispatch() mutated the caller's message priority to %q", msg.Priority)
+	}
+}
+
+type fakeScheduler struct{ fakeNotifier }
+
+func (f *fakeScheduler) MaxDelay() time.Duration { return time.Hour }
+
+func TestDispatch_Delay(t *testing.T) {
+	plain := &fakeNotifier{name: "plain"}
+	scheduler := &fakeScheduler{fakeNotifier{name: "sched"}}
+
+	dests := []Destination{
+		{Name: "plain", Notifier: plain},
+		{Name: "sched", Notifier: scheduler},
+	}
+
+	results := Dispatch(context.Background(), dests, &Message{Body: "later", Delay: 30 * time.Minute})
+	if results[0].Err == nil || len(plain.sent) != 0 {
+		t.Errorf("plain backend: err = %v, sent %d, want an error and nothing sent", results[0].Err, len(plain.sent))
+	}
+	if results[1].Err != nil || len(scheduler.sent) != 1 {
+		t.Errorf("scheduler: err = %v, sent %d, want it delivered", results[1].Err, len(scheduler.sent))
+	}
+
+	results = Dispatch(context.Background(), dests[1:], &Message{Body: "too late", Delay: 2 * time.Hour})
+	if results[0].Err == nil {
+		t.Error("Dispatch() should reject a delay beyond MaxDelay")
+	}
+}
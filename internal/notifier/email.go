@@ -0,0 +1,253 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("email", func(opts Options) (Notifier, error) {
+		e := &email{
+			server:   opts.Server,
+			user:     opts.User,
+			password: opts.Password,
+			from:     opts.Params["from"],
+			security: opts.Params["tls"],
+			auth:     opts.Params["auth"],
+		}
+		for _, addr := range strings.Split(opts.Params["to"], ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				e.to = append(e.to, addr)
+			}
+		}
+		if e.from == "" {
+			e.from = e.user
+		}
+		if e.security == "" {
+			e.security = "starttls"
+		}
+		if e.auth == "" && e.user != "" {
+			e.auth = "plain"
+		}
+		return e, nil
+	})
+}
+
+// defaultSMTPPorts is used when the server address has no port.
+var defaultSMTPPorts = map[string]string{
+	"starttls": "587",
+	"tls":      "465",
+	"none":     "25",
+}
+
+// email sends messages over SMTP. The server address comes from Server and
+// credentials from User/Password; sender, recipients, TLS mode and auth
+// mechanism are params.
+type email struct {
+	server   string
+	user     string
+	password string
+	from     string
+	to       []string
+	security string // starttls, tls or none
+	auth     string // plain, login or empty for no auth
+
+	rootCAs *x509.CertPool // overrides the system roots in tests
+}
+
+func (e *email) Name() string { return "email" }
+
+// String returns the recipients, for status messages.
+func (e *email) String() string { return strings.Join(e.to, ", ") }
+
+func (e *email) Validate() error {
+	if e.server == "" {
+		return fmt.Errorf("SMTP server is required — set it as the server (host or host:port)")
+	}
+	if e.from == "" {
+		return fmt.Errorf("email sender is required — set the 'from' param or a user")
+	}
+	if len(e.to) == 0 {
+		return fmt.Errorf("email recipients are required — set the 'to' param")
+	}
+	if _, ok := defaultSMTPPorts[e.security]; !ok {
+		return fmt.Errorf("unsupported email tls mode %q (use starttls, tls or none)", e.security)
+	}
+	switch e.auth {
+	case "", "plain", "login":
+		return nil
+	}
+	return fmt.Errorf("unsupported email auth %q (use plain or login)", e.auth)
+}
+
+func (e *email) Send(ctx context.Context, msg *Message) error {
+	addr := e.server
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, defaultSMTPPorts[e.security])
+	}
+	host, _, _ := net.SplitHostPort(addr)
+	tlsConfig := &tls.Config{ServerName: host, RootCAs: e.rootCAs, MinVersion: tls.VersionTLS12}
+
+	conn, err := e.dial(ctx, addr, tlsConfig)
+	if err != nil {
+		return fmt.Errorf("connecting to SMTP server: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("starting SMTP session: %w", err)
+	}
+	defer c.Close() //nolint:errcheck
+
+	if e.security == "starttls" {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS — set the 'tls' param to 'tls' or 'none'")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS: %w", err)
+		}
+	}
+
+	if e.auth != "" {
+		var a smtp.Auth
+		if e.auth == "login" {
+			a = &loginAuth{host: host, username: e.user, password: e.password}
+		} else {
+			a = smtp.PlainAuth("", e.user, e.password, host)
+		}
+		if err := c.Auth(a); err != nil {
+			return fmt.Errorf("SMTP auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(e.from); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM: %w", err)
+	}
+	for _, rcpt := range e.to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA: %w", err)
+	}
+	if _, err := w.Write(e.compose(msg)); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP DATA: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// dial opens the connection, wrapping it in TLS straight away for
+// implicit-TLS (SMTPS) servers.
+func (e *email) dial(ctx context.Context, addr string, tlsConfig *tls.Config) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if e.security == "tls" {
+		td := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+		return td.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// compose renders msg as an RFC 5322 message with a quoted-printable body.
+func (e *email) compose(msg *Message) []byte {
+	subject := msg.Title
+	if subject == "" {
+		subject = "term_notify"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	switch msg.Priority {
+	case "max", "urgent", "5":
+		buf.WriteString("X-Priority: 1\r\n")
+	case "high", "4":
+		buf.WriteString("X-Priority: 2\r\n")
+	case "low", "2":
+		buf.WriteString("X-Priority: 4\r\n")
+	case "min", "1":
+		buf.WriteString("X-Priority: 5\r\n")
+	}
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	body := msg.Body
+	if run := msg.Run; run != nil {
+		body = fmt.Sprintf("Command:   %s\nDuration:  %s\nExit code: %d\n",
+			run.Command, FormatDuration(run.Duration), run.ExitCode)
+		if run.Host != "" {
+			body += fmt.Sprintf("Host:      %s\n", run.Host)
+		}
+	}
+
+	qp := quotedprintable.NewWriter(&buf)
+	_, _ = qp.Write([]byte(strings.ReplaceAll(body, "\n", "\r\n")))
+	_ = qp.Close()
+	buf.WriteString("\r\n")
+
+	return buf.Bytes()
+}
+
+// loginAuth implements the AUTH LOGIN mechanism, which net/smtp lacks but
+// many corporate relays still require.
+type loginAuth struct {
+	host     string
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// Like smtp.PlainAuth, refuse to send credentials in the clear except
+	// to localhost.
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected LOGIN challenge %q", fromServer)
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
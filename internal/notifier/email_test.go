@@ -0,0 +1,303 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a minimal in-process SMTP server that records what a client
+// sends. It advertises STARTTLS when tlsConfig is set, or speaks TLS from
+// the first byte when implicitTLS is also set.
+type fakeSMTP struct {
+	ln          net.Listener
+	tlsConfig   *tls.Config
+	implicitTLS bool
+
+	mu       sync.Mutex
+	usedTLS  bool
+	authMech string
+	authCred string
+	from     string
+	rcpts    []string
+	data     string
+	done     chan struct{}
+}
+
+func startFakeSMTP(t *testing.T, tlsConfig *tls.Config, implicitTLS bool) *fakeSMTP {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if implicitTLS {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+
+	f := &fakeSMTP{ln: ln, tlsConfig: tlsConfig, implicitTLS: implicitTLS, done: make(chan struct{})}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer close(f.done)
+		f.serve(conn)
+	}()
+	return f
+}
+
+func (f *fakeSMTP) addr() string { return f.ln.Addr().String() }
+
+func (f *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close() //nolint:errcheck
+
+	secure := f.implicitTLS
+	r := bufio.NewReader(conn)
+	reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+	readLine := func() string {
+		line, _ := r.ReadString('\n')
+		return strings.TrimRight(line, "\r\n")
+	}
+	decode := func(s string) string {
+		b, _ := base64.StdEncoding.DecodeString(s)
+		return string(b)
+	}
+
+	reply("220 fake ESMTP")
+	for {
+		line := readLine()
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		f.mu.Lock()
+		switch verb {
+		case "EHLO":
+			reply("250-fake")
+			if f.tlsConfig != nil && !secure {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN LOGIN")
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, f.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				f.mu.Unlock()
+				return
+			}
+			conn, r, secure = tlsConn, bufio.NewReader(tlsConn), true
+			f.usedTLS = true
+		case "AUTH":
+			fields := strings.Fields(line)
+			f.authMech = fields[1]
+			if fields[1] == "PLAIN" {
+				f.authCred = decode(fields[2])
+			} else {
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+				user := decode(readLine())
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+				f.authCred = user + ":" + decode(readLine())
+			}
+			reply("235 authenticated")
+		case "MAIL":
+			f.from = line
+			reply("250 ok")
+		case "RCPT":
+			f.rcpts = append(f.rcpts, line)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l := readLine()
+				if l == "." {
+					break
+				}
+				data.WriteString(l + "\n")
+			}
+			f.data = data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			f.mu.Unlock()
+			return
+		default:
+			reply("502 unrecognized")
+		}
+		if secure {
+			f.usedTLS = true
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *fakeSMTP) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-f.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP session did not finish")
+	}
+}
+
+// testTLS borrows httptest's self-signed certificate for 127.0.0.1.
+func testTLS(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+	ts := httptest.NewTLSServer(nil)
+	t.Cleanup(ts.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	return ts.TLS.Clone(), pool
+}
+
+func TestEmail_SendPlainAuth(t *testing.T) {
+	srv := startFakeSMTP(t, nil, false)
+
+	n, err := New("email", Options{
+		Server:   srv.addr(),
+		User:     "bot@example.com",
+		Password: "s3cret",
+		Params:   map[string]string{"to": "a@example.com, b@example.com", "tls": "none"},
+	})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := n.Validate(); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title:    "❌ Command Failed",
+		Priority: "high",
+		Run:      &RunInfo{Command: "make release", ExitCode: 2, Duration: 90 * time.Second, Host: "ci-01"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	srv.wait(t)
+
+	if srv.authMech != "PLAIN" || srv.authCred != "\x00bot@example.com\x00s3cret" {
+		t.Errorf("auth = %s %q, want PLAIN with the configured credentials", srv.authMech, srv.authCred)
+	}
+	if srv.from != "MAIL FROM:<bot@example.com>" {
+		t.Errorf("MAIL = %q, want sender defaulting to the user", srv.from)
+	}
+	if len(srv.rcpts) != 2 || !strings.Contains(srv.rcpts[1], "b@example.com") {
+		t.Errorf("RCPT = %v, want both recipients", srv.rcpts)
+	}
+
+	header, body, _ := strings.Cut(srv.data, "\n\n")
+	if !strings.Contains(header, "Subject: =?utf-8?q?") {
+		t.Errorf("headers missing encoded subject:\n%s", header)
+	}
+	if !strings.Contains(header, "X-Priority: 2") {
+		t.Errorf("headers missing X-Priority for high priority:\n%s", header)
+	}
+	decoded, _ := readQP(body)
+	for _, want := range []string{"Command:   make release", "Duration:  1m 30s", "Exit code: 2", "Host:      ci-01"} {
+		if !strings.Contains(decoded, want) {
+			t.Errorf("body missing %q:\n%s", want, decoded)
+		}
+	}
+}
+
+func TestEmail_StartTLSLoginAuth(t *testing.T) {
+	serverTLS, roots := testTLS(t)
+	srv := startFakeSMTP(t, serverTLS, false)
+
+	n := &email{
+		server:   srv.addr(),
+		user:     "bot",
+		password: "pw",
+		from:     "tn@example.com",
+		to:       []string{"ops@example.com"},
+		security: "starttls",
+		auth:     "login",
+		rootCAs:  roots,
+	}
+	if err := n.Send(context.Background(), &Message{Title: "hi", Body: "there"}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	srv.wait(t)
+
+	if !srv.usedTLS {
+		t.Error("session was not upgraded with STARTTLS")
+	}
+	if srv.authMech != "LOGIN" || srv.authCred != "bot:pw" {
+		t.Errorf("auth = %s %q, want LOGIN bot:pw", srv.authMech, srv.authCred)
+	}
+}
+
+func TestEmail_ImplicitTLS(t *testing.T) {
+	serverTLS, roots := testTLS(t)
+	srv := startFakeSMTP(t, serverTLS, true)
+
+	n := &email{
+		server:   srv.addr(),
+		from:     "tn@example.com",
+		to:       []string{"ops@example.com"},
+		security: "tls",
+		rootCAs:  roots,
+	}
+	if err := n.Send(context.Background(), &Message{Title: "hi", Body: "there"}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	srv.wait(t)
+
+	if !srv.usedTLS {
+		t.Error("session did not use implicit TLS")
+	}
+	if srv.authMech != "" {
+		t.Errorf("auth = %q, want none without a user", srv.authMech)
+	}
+}
+
+func TestEmail_StartTLSUnsupported(t *testing.T) {
+	srv := startFakeSMTP(t, nil, false)
+
+	n := &email{server: srv.addr(), from: "a@x", to: []string{"b@x"}, security: "starttls"}
+	if err := n.Send(context.Background(), &Message{}); err == nil {
+		t.Fatal("Send() expected error when the server lacks STARTTLS, got nil")
+	}
+}
+
+func TestEmail_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"missing server", Options{User: "a@x", Params: map[string]string{"to": "b@x"}}, true},
+		{"missing sender", Options{Server: "smtp.x", Params: map[string]string{"to": "b@x"}}, true},
+		{"missing recipients", Options{Server: "smtp.x", User: "a@x"}, true},
+		{"bad tls mode", Options{Server: "smtp.x", User: "a@x", Params: map[string]string{"to": "b@x", "tls": "ssl"}}, true},
+		{"bad auth", Options{Server: "smtp.x", User: "a@x", Params: map[string]string{"to": "b@x", "auth": "cram-md5"}}, true},
+		{"valid", Options{Server: "smtp.x", User: "a@x", Params: map[string]string{"to": "b@x"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, _ := New("email", tt.opts)
+			if err := n.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func readQP(s string) (string, error) {
+	b, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(s)))
+	return string(b), err
+}
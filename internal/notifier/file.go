@@ -0,0 +1,214 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("file", func(opts Options) (Notifier, error) {
+		f := &fileSink{path: opts.Params["path"], maxSize: 10 << 20, maxFiles: 5}
+		if rest, ok := strings.CutPrefix(f.path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				f.path = filepath.Join(home, rest)
+			}
+		}
+		if s := opts.Params["max_size"]; s != "" {
+			size, err := parseSize(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid file max_size %q: %w", s, err)
+			}
+			f.maxSize = size
+		}
+		if s := opts.Params["max_files"]; s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid file max_files %q", s)
+			}
+			f.maxFiles = n
+		}
+		return f, nil
+	})
+}
+
+// fileSink appends notifications to a JSON Lines file for auditing. As a
+// Recorder it writes one line per destination with that destination's
+// delivery result. When the file would grow past maxSize it is renamed to
+// path.1 (shifting older files up to path.<maxFiles>) and a new one is
+// started.
+type fileSink struct {
+	path     string
+	maxSize  int64 // 0 disables rotation
+	maxFiles int
+
+	mu sync.Mutex
+}
+
+// fileRecord is one line of the audit file.
+type fileRecord struct {
+	Time        time.Time `json:"time"`
+	Destination string    `json:"destination,omitempty"`
+	Backend     string    `json:"backend,omitempty"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	Attempts    int       `json:"attempts,omitempty"`
+	Title       string    `json:"title"`
+	Body        string    `json:"body"`
+	Priority    string    `json:"priority"`
+	Tags        []string  `json:"tags"`
+	Command     string    `json:"command,omitempty"`
+	ExitCode    *int      `json:"exit_code,omitempty"`
+	DurationMS  int64     `json:"duration_ms,omitempty"`
+	Host        string    `json:"host"`
+}
+
+func (f *fileSink) Name() string { return "file" }
+
+// String returns the file path, for status messages.
+func (f *fileSink) String() string { return f.path }
+
+func (f *fileSink) Validate() error {
+	if f.path == "" {
+		return fmt.Errorf("file path is required — set the 'path' param")
+	}
+	return nil
+}
+
+// Send logs msg on its own, for when no other destination is involved.
+func (f *fileSink) Send(ctx context.Context, msg *Message) error {
+	return f.write(f.record(msg, "logged"))
+}
+
+// Record logs msg once per delivery result.
+func (f *fileSink) Record(ctx context.Context, msg *Message, results []Result) error {
+	if len(results) == 0 {
+		return f.Send(ctx, msg)
+	}
+
+	records := make([]*fileRecord, len(results))
+	for i, r := range results {
+		rec := f.record(msg, "sent")
+		rec.Destination = r.Destination
+		rec.Backend = r.Backend
+		rec.Attempts = r.Attempts
+		if r.Err != nil {
+			rec.Status = "failed"
+			rec.Error = r.Err.Error()
+		}
+		records[i] = rec
+	}
+	return f.write(records...)
+}
+
+func (f *fileSink) record(msg *Message, status string) *fileRecord {
+	data := newRunEvent(msg)
+	rec := &fileRecord{
+		Time:     data.Time.UTC(),
+		Status:   status,
+		Title:    msg.Title,
+		Body:     msg.Body,
+		Priority: msg.Priority,
+		Tags:     data.Tags,
+		Host:     data.Host,
+	}
+	if msg.Run != nil {
+		rec.Command = data.Command
+		rec.ExitCode = &data.ExitCode
+		rec.DurationMS = data.DurationMS
+	}
+	return rec
+}
+
+// write appends records as JSON lines, rotating first if they would not fit.
+func (f *fileSink) write(records ...*fileRecord) error {
+	var buf []byte
+	for _, rec := range records {
+		line, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("encoding record: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return fmt.Errorf("creating log directory: %w", err)
+	}
+	if err := f.rotate(int64(len(buf))); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304 — path is user-configured
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	if _, err := file.Write(buf); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("writing log file: %w", err)
+	}
+	return file.Close()
+}
+
+// rotate shifts the log files along if adding n bytes to the current one
+// would exceed maxSize. A file that is still empty is never rotated.
+func (f *fileSink) rotate(n int64) error {
+	if f.maxSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(f.path)
+	if err != nil || info.Size() == 0 || info.Size()+n <= f.maxSize {
+		return nil
+	}
+
+	if f.maxFiles == 0 {
+		if err := os.Remove(f.path); err != nil {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+		return nil
+	}
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", f.path, f.maxFiles))
+	for i := f.maxFiles - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	return nil
+}
+
+// parseSize reads a byte count such as "512", "64KB", "10MB" or "1GiB".
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		scale  int64
+	}{
+		{"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+
+	scale := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s, scale = strings.TrimSpace(strings.TrimSuffix(s, u.suffix)), u.scale
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("not a size")
+	}
+	return n * scale, nil
+}
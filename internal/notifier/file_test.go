@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readRecords(t *testing.T, path string) []fileRecord {
+	t.Helper()
+	f, err := os.Open(path) // #nosec G304 — test temp dir
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	var records []fileRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec fileRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestFile_RecordsDispatchResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "tn.jsonl")
+	audit, err := New("file", Options{Params: map[string]string{"path": path}})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := audit.Validate(); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+
+	dests := []Destination{
+		{Name: "audit", Notifier: audit},
+		{Name: "phone", Notifier: &fakeNotifier{name: "ntfy"}},
+		{Name: "chat", Notifier: &fakeNotifier{name: "slack", err: errors.New("webhook gone")}},
+	}
+	msg := &Message{
+		Title: "❌ Command Failed", Body: "make", Priority: "high", Tags: "x",
+		Run: &RunInfo{Command: "make", ExitCode: 2, Duration: time.Second, Host: "ci"},
+	}
+
+	results := Dispatch(context.Background(), dests, msg)
+	if results[0].Err != nil {
+		t.Fatalf("audit result = %v, want nil", results[0].Err)
+	}
+
+	records := readRecords(t, path)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want one per delivering destination", len(records))
+	}
+	phone, chat := records[0], records[1]
+	if phone.Destination != "phone" || phone.Backend != "ntfy" || phone.Status != "sent" {
+		t.Errorf("phone record = %+v", phone)
+	}
+	if chat.Destination != "chat" || chat.Status != "failed" || chat.Error != "webhook gone" {
+		t.Errorf("chat record = %+v", chat)
+	}
+	if phone.Title != msg.Title || phone.Priority != "high" || len(phone.Tags) != 1 || phone.Host != "ci" {
+		t.Errorf("message fields = %+v", phone)
+	}
+	if phone.Command != "make" || phone.ExitCode == nil || *phone.ExitCode != 2 || phone.DurationMS != 1000 {
+		t.Errorf("run fields = %+v", phone)
+	}
+}
+
+func TestFile_SendAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tn.jsonl")
+	n, _ := New("file", Options{Params: map[string]string{"path": path}})
+
+	results := Dispatch(context.Background(), []Destination{{Name: "log", Notifier: n}}, &Message{Title: "hi"})
+	if results[0].Err != nil {
+		t.Fatalf("Dispatch() error = %v", results[0].Err)
+	}
+	records := readRecords(t, path)
+	if len(records) != 1 || records[0].Status != "logged" || records[0].ExitCode != nil {
+		t.Errorf("records = %+v, want one logged notification", records)
+	}
+}
+
+func TestFile_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tn.jsonl")
+	n, err := New("file", Options{Params: map[string]string{"path": path, "max_size": "1KB", "max_files": "2"}})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := n.Send(context.Background(), &Message{Title: "notification", Body: "0123456789012345678901234567890123456789"}); err != nil {
+			t.Fatalf("Send() returned unexpected error: %v", err)
+		}
+	}
+
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("%s: %v", filepath.Base(p), err)
+		}
+		if info.Size() > 1024 {
+			t.Errorf("%s is %d bytes, want at most 1024", filepath.Base(p), info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 should not exist with max_files 2", filepath.Base(path))
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"512", 512, false},
+		{"64KB", 64 << 10, false},
+		{"10mb", 10 << 20, false},
+		{"1 GiB", 1 << 30, false},
+		{"2M", 2 << 20, false},
+		{"lots", 0, true},
+		{"-1", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
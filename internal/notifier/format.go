@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Sidebar/embed colors used by the chat backends for command results.
+const (
+	colorSuccess = 0x2eb886
+	colorFailure = 0xa30200
+)
+
+// hexColor renders an RGB color as "#rrggbb".
+func hexColor(rgb int) string {
+	return fmt.Sprintf("#%06x", rgb)
+}
+
+// FormatDuration renders d compactly, e.g. "4.2s", "3m 5s" or "1h 2m 3s".
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	m := int(d.Minutes())
+	s := int(d.Seconds()) % 60
+	if d < time.Hour {
+		return fmt.Sprintf("%dm %ds", m, s)
+	}
+	h := int(d.Hours())
+	m = m % 60
+	return fmt.Sprintf("%dh %dm %ds", h, m, s)
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// runEvent is a message flattened into a single JSON-friendly record. It is
+// what webhook templates are rendered against and what the MQTT backend
+// publishes.
+type runEvent struct {
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	Priority   string    `json:"priority"`
+	Tags       []string  `json:"tags"`
+	Command    string    `json:"command,omitempty"`
+	ExitCode   int       `json:"exit_code"`
+	Success    bool      `json:"success"`
+	Duration   string    `json:"duration,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	Host       string    `json:"host"`
+	Cwd        string    `json:"cwd"`
+	Output     string    `json:"output,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+func newRunEvent(msg *Message) *runEvent {
+	data := &runEvent{
+		Title:    msg.Title,
+		Body:     msg.Body,
+		Priority: msg.Priority,
+		Tags:     []string{},
+		Success:  true,
+		Time:     time.Now(),
+	}
+	for _, tag := range strings.Split(msg.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			data.Tags = append(data.Tags, tag)
+		}
+	}
+
+	if run := msg.Run; run != nil {
+		data.Command = run.Command
+		data.ExitCode = run.ExitCode
+		data.Success = run.Succeeded()
+		data.Duration = FormatDuration(run.Duration)
+		data.DurationMS = run.Duration.Milliseconds()
+		data.Host = run.Host
+		data.Cwd = run.Cwd
+		data.Output = run.Output
+	}
+	if data.Host == "" {
+		data.Host, _ = os.Hostname()
+	}
+	if data.Cwd == "" {
+		data.Cwd, _ = os.Getwd()
+	}
+
+	return data
+}
@@ -1,4 +1,4 @@
-package cmd
+package notifier
 
 import (
 	"testing"
@@ -65,9 +65,9 @@ func TestFormatDuration(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := formatDuration(tt.input)
+			got := FormatDuration(tt.input)
 			if got != tt.expected {
-				t.Errorf("formatDuration(%v) = %q, want %q", tt.input, got, tt.expected)
+				t.Errorf("FormatDuration(%v) = %q, want %q", tt.input, got, tt.expected)
 			}
 		})
 	}
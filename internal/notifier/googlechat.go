@@ -0,0 +1,128 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register("googlechat", func(opts Options) (Notifier, error) {
+		return &googleChat{webhook: opts.Server}, nil
+	})
+}
+
+// googleChat posts a card to a Google Chat space's incoming webhook.
+type googleChat struct {
+	webhook string
+}
+
+type gchatPayload struct {
+	Text    string      `json:"text,omitempty"`
+	CardsV2 []gchatCard `json:"cardsV2"`
+}
+
+type gchatCard struct {
+	CardID string        `json:"cardId"`
+	Card   gchatCardBody `json:"card"`
+}
+
+type gchatCardBody struct {
+	Header   *gchatHeader   `json:"header,omitempty"`
+	Sections []gchatSection `json:"sections"`
+}
+
+type gchatHeader struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+}
+
+type gchatSection struct {
+	Header                    string        `json:"header,omitempty"`
+	Collapsible               bool          `json:"collapsible,omitempty"`
+	UncollapsibleWidgetsCount int           `json:"uncollapsibleWidgetsCount,omitempty"`
+	Widgets                   []gchatWidget `json:"widgets"`
+}
+
+// gchatWidget holds exactly one of its fields.
+type gchatWidget struct {
+	DecoratedText *gchatDecoratedText `json:"decoratedText,omitempty"`
+	TextParagraph *gchatText          `json:"textParagraph,omitempty"`
+}
+
+type gchatDecoratedText struct {
+	TopLabel string `json:"topLabel"`
+	Text     string `json:"text"`
+}
+
+type gchatText struct {
+	Text string `json:"text"`
+}
+
+func (g *googleChat) Name() string { return "googlechat" }
+
+// String returns the webhook host; the full URL embeds a key and token.
+func (g *googleChat) String() string {
+	if u, err := url.Parse(g.webhook); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "googlechat"
+}
+
+func (g *googleChat) Validate() error {
+	if g.webhook == "" {
+		return fmt.Errorf("google chat webhook URL is required — set it as the server")
+	}
+	return nil
+}
+
+func (g *googleChat) Send(ctx context.Context, msg *Message) error {
+	_, err := doJSON(ctx, "googlechat", "POST", g.webhook, nil, googleChatMessage(msg))
+	return err
+}
+
+// googleChatMessage renders msg as a card. Command results get one widget
+// per detail, a colored status line and, if captured, a collapsible
+// section with the tail of the output. Card text is a small HTML subset,
+// so everything from the message is escaped.
+func googleChatMessage(msg *Message) *gchatPayload {
+	card := gchatCardBody{Header: &gchatHeader{Title: msg.Title}}
+
+	if run := msg.Run; run != nil {
+		card.Header.Subtitle = run.Host
+
+		status, color := "Succeeded", hexColor(colorSuccess)
+		if !run.Succeeded() {
+			status, color = "Failed", hexColor(colorFailure)
+		}
+		details := gchatSection{Widgets: []gchatWidget{
+			{DecoratedText: &gchatDecoratedText{TopLabel: "Command", Text: html.EscapeString(run.Command)}},
+			{DecoratedText: &gchatDecoratedText{TopLabel: "Status", Text: fmt.Sprintf(`<font color="%s">%s</font>`, color, status)}},
+			{DecoratedText: &gchatDecoratedText{TopLabel: "Duration", Text: FormatDuration(run.Duration)}},
+			{DecoratedText: &gchatDecoratedText{TopLabel: "Exit code", Text: strconv.Itoa(run.ExitCode)}},
+		}}
+		card.Sections = append(card.Sections, details)
+
+		if run.Output != "" {
+			card.Sections = append(card.Sections, gchatSection{
+				Header:      "Output",
+				Collapsible: true,
+				Widgets: []gchatWidget{{TextParagraph: &gchatText{
+					Text: strings.ReplaceAll(html.EscapeString(run.Output), "\n", "<br>"),
+				}}},
+			})
+		}
+	} else {
+		card.Sections = append(card.Sections, gchatSection{Widgets: []gchatWidget{
+			{TextParagraph: &gchatText{Text: strings.ReplaceAll(html.EscapeString(msg.Body), "\n", "<br>")}},
+		}})
+	}
+
+	return &gchatPayload{
+		Text:    msg.Title,
+		CardsV2: []gchatCard{{CardID: "tn-result", Card: card}},
+	}
+}
@@ -0,0 +1,65 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoogleChat_SendRunResult(t *testing.T) {
+	var payload gchatPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	n, err := New("googlechat", Options{Server: server.URL + "/v1/spaces/AAA/messages?key=k&token=t"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title: "✅ Command Succeeded",
+		Run:   &RunInfo{Command: "echo <hi> && true", Duration: time.Second, Host: "box", Output: "<hi>\ndone"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if len(payload.CardsV2) != 1 {
+		t.Fatalf("len(cardsV2) = %d, want 1", len(payload.CardsV2))
+	}
+	card := payload.CardsV2[0].Card
+	if card.Header.Title != msg.Title || card.Header.Subtitle != "box" {
+		t.Errorf("header = %+v", card.Header)
+	}
+	if len(card.Sections) != 2 {
+		t.Fatalf("len(sections) = %d, want details and output", len(card.Sections))
+	}
+
+	widgets := card.Sections[0].Widgets
+	if got := widgets[0].DecoratedText.Text; got != "echo &lt;hi&gt; &amp;&amp; true" {
+		t.Errorf("command = %q, want it HTML-escaped", got)
+	}
+	if got := widgets[1].DecoratedText.Text; !strings.Contains(got, "#2eb886") || !strings.Contains(got, "Succeeded") {
+		t.Errorf("status = %q, want a green Succeeded", got)
+	}
+
+	output := card.Sections[1]
+	if !output.Collapsible || output.Widgets[0].TextParagraph.Text != "&lt;hi&gt;<br>done" {
+		t.Errorf("output section = %+v", output)
+	}
+}
+
+func TestGoogleChat_Validate(t *testing.T) {
+	n, _ := New("googlechat", Options{})
+	if err := n.Validate(); err == nil {
+		t.Error("Validate() expected error without a webhook URL, got nil")
+	}
+}
@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+func init() {
+	Register("gotify", func(opts Options) (Notifier, error) {
+		return &gotify{server: opts.Server, token: opts.Token}, nil
+	})
+}
+
+// gotify pushes messages to a Gotify server using an application token.
+type gotify struct {
+	server string
+	token  string
+}
+
+// gotifyPriorities maps ntfy-style priority names and numbers onto
+// Gotify's 0–10 scale.
+var gotifyPriorities = map[string]int{
+	"min":     1,
+	"1":       1,
+	"low":     3,
+	"2":       3,
+	"default": 5,
+	"3":       5,
+	"high":    8,
+	"4":       8,
+	"max":     10,
+	"urgent":  10,
+	"5":       10,
+}
+
+func (g *gotify) Name() string { return "gotify" }
+
+// String returns the server URL, for status messages.
+func (g *gotify) String() string { return baseURL(g.server) }
+
+func (g *gotify) Validate() error {
+	if g.server == "" {
+		return fmt.Errorf("gotify server is required — run 'tn config --server <url>' or set TN_SERVER")
+	}
+	if g.token == "" {
+		return fmt.Errorf("gotify app token is required — run 'tn config --token <token>' or set TN_TOKEN")
+	}
+	return nil
+}
+
+func (g *gotify) Send(ctx context.Context, msg *Message) error {
+	priority, ok := gotifyPriorities[msg.Priority]
+	if !ok {
+		priority = gotifyPriorities["default"]
+	}
+
+	payload := struct {
+		Title    string `json:"title,omitempty"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+	}{
+		Title:    msg.Title,
+		Message:  msg.Body,
+		Priority: priority,
+	}
+
+	header := http.Header{}
+	header.Set("X-Gotify-Key", g.token)
+
+	_, err := doJSON(ctx, "gotify", "POST", baseURL(g.server)+"/message", header, payload)
+	return err
+}
@@ -0,0 +1,112 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGotify_Send(t *testing.T) {
+	var capturedReq *http.Request
+	var payload struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r.Clone(r.Context())
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n, err := New("gotify", Options{Server: server.URL, Token: "AppToken123"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{Title: "Test Title", Body: "Test Body", Priority: "high"}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if capturedReq.URL.Path != "/message" {
+		t.Errorf("request path = %q, want %q", capturedReq.URL.Path, "/message")
+	}
+	if got := capturedReq.Header.Get("X-Gotify-Key"); got != "AppToken123" {
+		t.Errorf("X-Gotify-Key header = %q, want %q", got, "AppToken123")
+	}
+	if payload.Title != "Test Title" || payload.Message != "Test Body" {
+		t.Errorf("payload = %+v, want title/message from msg", payload)
+	}
+	if payload.Priority != 8 {
+		t.Errorf("priority = %d, want 8", payload.Priority)
+	}
+}
+
+func TestGotify_PriorityMapping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"min", 1},
+		{"low", 3},
+		{"default", 5},
+		{"", 5},
+		{"high", 8},
+		{"max", 10},
+		{"urgent", 10},
+		{"4", 8},
+		{"bogus", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var got int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var payload struct {
+					Priority int `json:"priority"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&payload)
+				got = payload.Priority
+			}))
+			defer server.Close()
+
+			n := &gotify{server: server.URL, token: "tok"}
+			if err := n.Send(context.Background(), &Message{Priority: tt.input}); err != nil {
+				t.Fatalf("Send() returned unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("priority for %q = %d, want %d", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGotify_Validate(t *testing.T) {
+	if err := (&gotify{server: "gotify.example.com"}).Validate(); err == nil {
+		t.Error("Validate() expected error for missing token, got nil")
+	}
+	if err := (&gotify{token: "tok"}).Validate(); err == nil {
+		t.Error("Validate() expected error for missing server, got nil")
+	}
+	if err := (&gotify{server: "gotify.example.com", token: "tok"}).Validate(); err != nil {
+		t.Errorf("Validate() returned unexpected error: %v", err)
+	}
+}
+
+func TestGotify_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Unauthorized"}`))
+	}))
+	defer server.Close()
+
+	n := &gotify{server: server.URL, token: "bad"}
+	if err := n.Send(context.Background(), &Message{Body: "x"}); err == nil {
+		t.Fatal("Send() expected error for 401 response, got nil")
+	}
+}
@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// baseURL ensures server has a scheme, defaulting to https, and strips any
+// trailing slash so paths can be appended directly.
+func baseURL(server string) string {
+	if !strings.HasPrefix(server, "http://") && !strings.HasPrefix(server, "https://") {
+		server = "https://" + server
+	}
+	return strings.TrimRight(server, "/")
+}
+
+// HTTPError reports a non-2xx response from a backend's server.
+type HTTPError struct {
+	Backend    string
+	StatusCode int
+	Body       string
+	Header     http.Header
+	// RetryAfter is how long the server asked the client to wait before
+	// trying again, or 0 if it did not say.
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s server returned %d: %s", e.Backend, e.StatusCode, e.Body)
+}
+
+// doJSON sends payload as a JSON request body and returns the response body.
+// A nil payload sends no body, for GET requests.
+func doJSON(ctx context.Context, backend, method, url string, header http.Header, payload any) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("encoding payload: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return do(backend, req)
+}
+
+// uploadClient sends request bodies that can take a while on a slow link,
+// such as attachments. It has no overall timeout, so the request's context
+// bounds the upload, but the server must answer promptly once it is sent.
+var uploadClient = newUploadClient()
+
+func newUploadClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 10 * time.Second
+	return &http.Client{Transport: transport}
+}
+
+// do sends req and returns the response body. Non-2xx responses are
+// reported as an *HTTPError attributed to backend.
+func do(backend string, req *http.Request) ([]byte, error) {
+	return doWith(&http.Client{Timeout: 10 * time.Second}, backend, req)
+}
+
+// doWith is do with a given client.
+func doWith(client *http.Client, backend string, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req) // #nosec G704 — URL is user-configured
+	if err != nil {
+		return nil, fmt.Errorf("sending notification: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		httpErr := &HTTPError{
+			Backend:    backend,
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			Header:     resp.Header,
+		}
+		httpErr.RetryAfter, _ = retryAfter(resp.Header, time.Now())
+		return nil, httpErr
+	}
+
+	return body, nil
+}
@@ -0,0 +1,226 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+func init() {
+	Register("pagerduty", func(opts Options) (Notifier, error) {
+		return newIncident("pagerduty", "https://events.pagerduty.com", opts)
+	})
+	Register("opsgenie", func(opts Options) (Notifier, error) {
+		return newIncident("opsgenie", "https://api.opsgenie.com", opts)
+	})
+}
+
+// incident opens an alert in PagerDuty (Events API v2) or Opsgenie when a
+// command fails and resolves it when the same command later succeeds on
+// the same host. Both events carry a dedup key derived from the command
+// and host, so repeated failures update one incident rather than opening
+// several. Messages that do not report a run, such as tn notify and
+// tn pid, are skipped unless the trigger_messages param is set.
+type incident struct {
+	name     string
+	server   string
+	key      string // PagerDuty routing key or Opsgenie API key
+	dedupKey string // overrides the derived key when set
+	messages bool   // trigger for messages without a run, too
+}
+
+func newIncident(name, defaultServer string, opts Options) (*incident, error) {
+	i := &incident{name: name, server: opts.Server, key: opts.Token, dedupKey: opts.Params["dedup_key"]}
+	if i.server == "" {
+		i.server = defaultServer
+	}
+	if s := opts.Params["trigger_messages"]; s != "" {
+		var err error
+		if i.messages, err = strconv.ParseBool(s); err != nil {
+			return nil, fmt.Errorf("invalid %s trigger_messages %q (use true or false)", name, s)
+		}
+	}
+	return i, nil
+}
+
+// pagerdutySeverities and opsgeniePriorities map ntfy-style priorities onto
+// each service's levels.
+var (
+	pagerdutySeverities = map[string]string{
+		"min": "info", "1": "info",
+		"low": "warning", "2": "warning",
+		"default": "error", "3": "error",
+		"high": "error", "4": "error",
+		"max": "critical", "urgent": "critical", "5": "critical",
+	}
+	opsgeniePriorities = map[string]string{
+		"min": "P5", "1": "P5",
+		"low": "P4", "2": "P4",
+		"default": "P3", "3": "P3",
+		"high": "P2", "4": "P2",
+		"max": "P1", "urgent": "P1", "5": "P1",
+	}
+)
+
+func (i *incident) Name() string { return i.name }
+
+// String returns the API host, for status messages.
+func (i *incident) String() string {
+	if u, err := url.Parse(baseURL(i.server)); err == nil {
+		return u.Host
+	}
+	return i.name
+}
+
+func (i *incident) Validate() error {
+	if i.key == "" {
+		if i.name == "pagerduty" {
+			return fmt.Errorf("pagerduty routing key is required — set it as the token")
+		}
+		return fmt.Errorf("opsgenie API key is required — set it as the token")
+	}
+	return nil
+}
+
+// Send triggers an incident for a failed run, or resolves it for a
+// successful one. Messages that do not report a run have no outcome to
+// act on; they trigger only when the destination asks for it.
+func (i *incident) Send(ctx context.Context, msg *Message) error {
+	if msg.Run == nil && !i.messages {
+		return nil
+	}
+	resolve := msg.Run != nil && msg.Run.Succeeded()
+	if i.name == "pagerduty" {
+		return i.sendPagerDuty(ctx, msg, resolve)
+	}
+	return i.sendOpsgenie(ctx, msg, resolve)
+}
+
+// dedup returns the key that ties a failure to its later resolution:
+// a hash of the host and command, or of the host and title for messages
+// that do not report a run.
+func (i *incident) dedup(msg *Message) string {
+	if i.dedupKey != "" {
+		return i.dedupKey
+	}
+
+	host, subject := "", msg.Title
+	if run := msg.Run; run != nil {
+		host, subject = run.Host, run.Command
+	}
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+
+	sum := sha256.Sum256([]byte(host + "\x00" + subject))
+	return "tn-" + hex.EncodeToString(sum[:8])
+}
+
+func (i *incident) sendPagerDuty(ctx context.Context, msg *Message, resolve bool) error {
+	type pdPayload struct {
+		Summary       string    `json:"summary"`
+		Source        string    `json:"source"`
+		Severity      string    `json:"severity"`
+		Component     string    `json:"component,omitempty"`
+		CustomDetails *runEvent `json:"custom_details,omitempty"`
+	}
+	event := struct {
+		RoutingKey  string     `json:"routing_key"`
+		EventAction string     `json:"event_action"`
+		DedupKey    string     `json:"dedup_key"`
+		Client      string     `json:"client"`
+		Payload     *pdPayload `json:"payload,omitempty"`
+	}{
+		RoutingKey:  i.key,
+		EventAction: "resolve",
+		DedupKey:    i.dedup(msg),
+		Client:      "term_notify",
+	}
+
+	if !resolve {
+		data := newRunEvent(msg)
+		severity, ok := pagerdutySeverities[msg.Priority]
+		if !ok {
+			severity = pagerdutySeverities["default"]
+		}
+		event.EventAction = "trigger"
+		event.Payload = &pdPayload{
+			Summary:       incidentSummary(msg, data.Host),
+			Source:        data.Host,
+			Severity:      severity,
+			Component:     data.Command,
+			CustomDetails: data,
+		}
+	}
+
+	_, err := doJSON(ctx, "pagerduty", "POST", baseURL(i.server)+"/v2/enqueue", nil, event)
+	return err
+}
+
+func (i *incident) sendOpsgenie(ctx context.Context, msg *Message, resolve bool) error {
+	header := http.Header{}
+	header.Set("Authorization", "GenieKey "+i.key)
+	alias := i.dedup(msg)
+
+	if resolve {
+		target := baseURL(i.server) + "/v2/alerts/" + url.PathEscape(alias) + "/close?identifierType=alias"
+		_, err := doJSON(ctx, "opsgenie", "POST", target, header, map[string]string{"source": "term_notify"})
+
+		// Closing an alert that was never opened is not a failure.
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+
+	data := newRunEvent(msg)
+	priority, ok := opsgeniePriorities[msg.Priority]
+	if !ok {
+		priority = opsgeniePriorities["default"]
+	}
+	details := map[string]string{"host": data.Host, "cwd": data.Cwd}
+	if msg.Run != nil {
+		details["command"] = data.Command
+		details["exit_code"] = strconv.Itoa(data.ExitCode)
+		details["duration"] = data.Duration
+	}
+
+	alert := struct {
+		Message     string            `json:"message"`
+		Alias       string            `json:"alias"`
+		Description string            `json:"description,omitempty"`
+		Priority    string            `json:"priority"`
+		Source      string            `json:"source"`
+		Tags        []string          `json:"tags,omitempty"`
+		Details     map[string]string `json:"details"`
+	}{
+		Message:     truncate(incidentSummary(msg, data.Host), 130),
+		Alias:       alias,
+		Description: msg.Body,
+		Priority:    priority,
+		Source:      "term_notify",
+		Tags:        data.Tags,
+		Details:     details,
+	}
+
+	_, err := doJSON(ctx, "opsgenie", "POST", baseURL(i.server)+"/v2/alerts", header, alert)
+	return err
+}
+
+// incidentSummary is the one-line incident title.
+func incidentSummary(msg *Message, host string) string {
+	if run := msg.Run; run != nil {
+		return fmt.Sprintf("%s failed on %s (exit code %d)", run.Command, host, run.ExitCode)
+	}
+	if msg.Title != "" {
+		return msg.Title
+	}
+	return msg.Body
+}
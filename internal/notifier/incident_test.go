@@ -0,0 +1,168 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type capturedEvent struct {
+	Path   string
+	Query  string
+	Auth   string
+	Fields map[string]any
+}
+
+// incidentServer records each request's path and JSON body and answers
+// with status.
+func incidentServer(t *testing.T, status int) (*httptest.Server, *[]capturedEvent) {
+	t.Helper()
+	var events []capturedEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := capturedEvent{Path: r.URL.Path, Query: r.URL.RawQuery, Auth: r.Header.Get("Authorization")}
+		_ = json.NewDecoder(r.Body).Decode(&e.Fields)
+		events = append(events, e)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &events
+}
+
+func TestPagerDuty_TriggerThenResolve(t *testing.T) {
+	server, events := incidentServer(t, http.StatusAccepted)
+
+	n, err := New("pagerduty", Options{Server: server.URL, Token: "R0UT1NG"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	failed := &Message{Priority: "max", Run: &RunInfo{Command: "backup.sh", ExitCode: 3, Duration: time.Minute, Host: "db1"}}
+	if err := n.Send(context.Background(), failed); err != nil {
+		t.Fatalf("Send(failure) returned unexpected error: %v", err)
+	}
+	succeeded := &Message{Run: &RunInfo{Command: "backup.sh", Host: "db1"}}
+	if err := n.Send(context.Background(), succeeded); err != nil {
+		t.Fatalf("Send(success) returned unexpected error: %v", err)
+	}
+
+	if len(*events) != 2 {
+		t.Fatalf("server received %d events, want 2", len(*events))
+	}
+	trigger, resolve := (*events)[0], (*events)[1]
+
+	if trigger.Path != "/v2/enqueue" {
+		t.Errorf("path = %q, want /v2/enqueue", trigger.Path)
+	}
+	if trigger.Fields["event_action"] != "trigger" || resolve.Fields["event_action"] != "resolve" {
+		t.Errorf("actions = %v, %v, want trigger, resolve", trigger.Fields["event_action"], resolve.Fields["event_action"])
+	}
+	if trigger.Fields["routing_key"] != "R0UT1NG" {
+		t.Errorf("routing_key = %v, want R0UT1NG", trigger.Fields["routing_key"])
+	}
+	key, _ := trigger.Fields["dedup_key"].(string)
+	if !strings.HasPrefix(key, "tn-") || resolve.Fields["dedup_key"] != key {
+		t.Errorf("dedup keys = %q, %v, want matching tn- keys", key, resolve.Fields["dedup_key"])
+	}
+
+	payload, _ := trigger.Fields["payload"].(map[string]any)
+	if payload["severity"] != "critical" || payload["source"] != "db1" {
+		t.Errorf("payload = %v, want critical severity from db1", payload)
+	}
+	if summary, _ := payload["summary"].(string); !strings.Contains(summary, "backup.sh failed on db1") {
+		t.Errorf("summary = %q", summary)
+	}
+	if _, ok := resolve.Fields["payload"]; ok {
+		t.Error("resolve event should not carry a payload")
+	}
+}
+
+func TestIncident_DedupKey(t *testing.T) {
+	i := &incident{}
+	a := i.dedup(&Message{Run: &RunInfo{Command: "make", Host: "a"}})
+	b := i.dedup(&Message{Run: &RunInfo{Command: "make", Host: "b"}})
+	c := i.dedup(&Message{Run: &RunInfo{Command: "make test", Host: "a"}})
+	if a == b || a == c {
+		t.Errorf("dedup keys should differ by host and command: %q %q %q", a, b, c)
+	}
+	if again := i.dedup(&Message{Run: &RunInfo{Command: "make", Host: "a", ExitCode: 1}}); again != a {
+		t.Errorf("dedup key changed with exit code: %q != %q", again, a)
+	}
+
+	i.dedupKey = "nightly-backup"
+	if got := i.dedup(&Message{Run: &RunInfo{Command: "make"}}); got != "nightly-backup" {
+		t.Errorf("dedup() = %q, want the configured key", got)
+	}
+}
+
+func TestOpsgenie_TriggerThenResolve(t *testing.T) {
+	server, events := incidentServer(t, http.StatusAccepted)
+
+	n, _ := New("opsgenie", Options{Server: server.URL, Token: "genie"})
+	run := &RunInfo{Command: "deploy", ExitCode: 1, Host: "web"}
+	if err := n.Send(context.Background(), &Message{Priority: "high", Tags: "prod", Run: run}); err != nil {
+		t.Fatalf("Send(failure) returned unexpected error: %v", err)
+	}
+	run.ExitCode = 0
+	if err := n.Send(context.Background(), &Message{Run: run}); err != nil {
+		t.Fatalf("Send(success) returned unexpected error: %v", err)
+	}
+
+	trigger, resolve := (*events)[0], (*events)[1]
+	if trigger.Path != "/v2/alerts" || trigger.Auth != "GenieKey genie" {
+		t.Errorf("trigger path/auth = %q, %q", trigger.Path, trigger.Auth)
+	}
+	if trigger.Fields["priority"] != "P2" {
+		t.Errorf("priority = %v, want P2", trigger.Fields["priority"])
+	}
+	alias, _ := trigger.Fields["alias"].(string)
+	if want := "/v2/alerts/" + alias + "/close"; resolve.Path != want || resolve.Query != "identifierType=alias" {
+		t.Errorf("resolve = %s?%s, want %s?identifierType=alias", resolve.Path, resolve.Query, want)
+	}
+}
+
+func TestOpsgenie_ResolveUnknownAlert(t *testing.T) {
+	server, _ := incidentServer(t, http.StatusNotFound)
+
+	n, _ := New("opsgenie", Options{Server: server.URL, Token: "genie"})
+	if err := n.Send(context.Background(), &Message{Run: &RunInfo{Command: "true"}}); err != nil {
+		t.Errorf("Send() returned error closing an unknown alert: %v", err)
+	}
+}
+
+func TestIncident_MessagesWithoutRun(t *testing.T) {
+	for _, name := range []string{"pagerduty", "opsgenie"} {
+		server, events := incidentServer(t, http.StatusAccepted)
+		msg := &Message{Title: "🏁 Process Exited", Body: "PID 42 exited after 3m"}
+
+		n, _ := New(name, Options{Server: server.URL, Token: "k"})
+		if err := n.Send(context.Background(), msg); err != nil || len(*events) != 0 {
+			t.Errorf("%s: Send() = %v with %d events, want nothing sent", name, err, len(*events))
+		}
+
+		n, _ = New(name, Options{Server: server.URL, Token: "k", Params: map[string]string{"trigger_messages": "true"}})
+		if err := n.Send(context.Background(), msg); err != nil || len(*events) != 1 {
+			t.Errorf("%s: Send() with trigger_messages = %v with %d events, want one trigger", name, err, len(*events))
+		}
+
+		if _, err := New(name, Options{Token: "k", Params: map[string]string{"trigger_messages": "often"}}); err == nil {
+			t.Errorf("%s: New() expected error for an invalid trigger_messages, got nil", name)
+		}
+	}
+}
+
+func TestIncident_Validate(t *testing.T) {
+	for _, name := range []string{"pagerduty", "opsgenie"} {
+		n, _ := New(name, Options{})
+		if err := n.Validate(); err == nil {
+			t.Errorf("%s: Validate() expected error without a key, got nil", name)
+		}
+		n, _ = New(name, Options{Token: "k"})
+		if err := n.Validate(); err != nil {
+			t.Errorf("%s: Validate() returned unexpected error: %v", name, err)
+		}
+	}
+}
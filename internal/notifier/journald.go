@@ -0,0 +1,80 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register("journald", func(opts Options) (Notifier, error) {
+		j := &journald{socket: opts.Params["address"]}
+		if j.socket == "" {
+			j.socket = "/run/systemd/journal/socket"
+		}
+		return j, nil
+	})
+}
+
+// journald writes entries to the systemd journal using its native protocol,
+// so command results can be queried by field, e.g.
+// journalctl TN_EXIT_CODE=1.
+type journald struct {
+	socket string
+}
+
+func (j *journald) Name() string { return "journald" }
+
+func (j *journald) Validate() error { return nil }
+
+func (j *journald) Send(ctx context.Context, msg *Message) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unixgram", j.socket)
+	if err != nil {
+		return fmt.Errorf("connecting to journald: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	if _, err := conn.Write(journalEntry(msg)); err != nil {
+		return fmt.Errorf("writing to journald: %w", err)
+	}
+	return nil
+}
+
+// journalEntry encodes msg as a native-protocol datagram.
+func journalEntry(msg *Message) []byte {
+	var buf bytes.Buffer
+	field := func(key, value string) {
+		if !strings.Contains(value, "\n") {
+			buf.WriteString(key + "=" + value + "\n")
+			return
+		}
+		// Multi-line values are length-prefixed instead of newline-terminated.
+		buf.WriteString(key + "\n")
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value + "\n")
+	}
+
+	field("MESSAGE", strings.TrimSpace(msg.Title+"\n"+msg.Body))
+	field("PRIORITY", strconv.Itoa(severity(msg)))
+	field("SYSLOG_IDENTIFIER", "tn")
+	if msg.Title != "" {
+		field("TN_TITLE", msg.Title)
+	}
+	if msg.Tags != "" {
+		field("TN_TAGS", msg.Tags)
+	}
+	if run := msg.Run; run != nil {
+		field("TN_COMMAND", run.Command)
+		field("TN_EXIT_CODE", strconv.Itoa(run.ExitCode))
+		field("TN_DURATION_MS", strconv.FormatInt(run.Duration.Milliseconds(), 10))
+		if run.Cwd != "" {
+			field("TN_CWD", run.Cwd)
+		}
+	}
+
+	return buf.Bytes()
+}
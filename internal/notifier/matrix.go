@@ -0,0 +1,140 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func init() {
+	Register("matrix", func(opts Options) (Notifier, error) {
+		return &matrix{homeserver: opts.Server, token: opts.Token, room: opts.Topic}, nil
+	})
+}
+
+// matrixTxn makes transaction IDs unique within this process; the
+// timestamp prefix makes them unique across runs.
+var matrixTxn atomic.Uint64
+
+// matrix sends m.room.message events through the client-server API. The
+// homeserver comes from Server, the access token from Token and the room
+// ID or alias from Topic.
+type matrix struct {
+	homeserver string
+	token      string
+	room       string
+
+	// lastMsg is the message txnID was made for. A retry sends the same
+	// message again and reuses the ID, so the homeserver drops the
+	// duplicate if the first attempt got through.
+	mu      sync.Mutex
+	lastMsg *Message
+	txnID   string
+}
+
+func (m *matrix) Name() string { return "matrix" }
+
+// String returns the room, for status messages.
+func (m *matrix) String() string { return m.room }
+
+func (m *matrix) Validate() error {
+	if m.homeserver == "" {
+		return fmt.Errorf("matrix homeserver is required — run 'tn config --server <url>' or set TN_SERVER")
+	}
+	if m.token == "" {
+		return fmt.Errorf("matrix access token is required — run 'tn config --token <token>' or set TN_TOKEN")
+	}
+	if !strings.HasPrefix(m.room, "!") && !strings.HasPrefix(m.room, "#") {
+		return fmt.Errorf("matrix room must be a room ID (!id:server) or alias (#alias:server), got %q", m.room)
+	}
+	return nil
+}
+
+func (m *matrix) Send(ctx context.Context, msg *Message) error {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+m.token)
+
+	roomID, err := m.resolveRoom(ctx, header)
+	if err != nil {
+		return err
+	}
+
+	event := struct {
+		MsgType       string `json:"msgtype"`
+		Body          string `json:"body"`
+		Format        string `json:"format"`
+		FormattedBody string `json:"formatted_body"`
+	}{
+		MsgType:       "m.text",
+		Body:          strings.TrimSpace(msg.Title + "\n" + msg.Body),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: matrixHTML(msg),
+	}
+
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		baseURL(m.homeserver), url.PathEscape(roomID), url.PathEscape(m.txn(msg)))
+
+	_, err = doJSON(ctx, "matrix", "PUT", endpoint, header, event)
+	return err
+}
+
+// txn returns the transaction ID for msg, reusing the previous one when
+// msg is being retried.
+func (m *matrix) txn(msg *Message) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.lastMsg != msg {
+		m.lastMsg = msg
+		m.txnID = fmt.Sprintf("tn-%d-%d", time.Now().UnixNano(), matrixTxn.Add(1))
+	}
+	return m.txnID
+}
+
+// resolveRoom turns a room alias into a room ID; room IDs pass through.
+func (m *matrix) resolveRoom(ctx context.Context, header http.Header) (string, error) {
+	if !strings.HasPrefix(m.room, "#") {
+		return m.room, nil
+	}
+
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/directory/room/%s", baseURL(m.homeserver), url.PathEscape(m.room))
+	body, err := doJSON(ctx, "matrix", "GET", endpoint, header, nil)
+	if err != nil {
+		return "", fmt.Errorf("resolving room alias %s: %w", m.room, err)
+	}
+
+	var resp struct {
+		RoomID string `json:"room_id"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.RoomID == "" {
+		return "", fmt.Errorf("resolving room alias %s: unexpected response %q", m.room, body)
+	}
+	return resp.RoomID, nil
+}
+
+// matrixHTML renders msg as the HTML formatted_body of the event.
+func matrixHTML(msg *Message) string {
+	var b strings.Builder
+	if msg.Title != "" {
+		b.WriteString("<strong>" + html.EscapeString(msg.Title) + "</strong><br>")
+	}
+
+	if run := msg.Run; run != nil {
+		b.WriteString("<pre><code>" + html.EscapeString(run.Command) + "</code></pre>")
+		if run.Succeeded() {
+			fmt.Fprintf(&b, "Completed in %s", FormatDuration(run.Duration))
+		} else {
+			fmt.Fprintf(&b, "Failed in %s (exit code <code>%d</code>)", FormatDuration(run.Duration), run.ExitCode)
+		}
+		return b.String()
+	}
+
+	b.WriteString(strings.ReplaceAll(html.EscapeString(msg.Body), "\n", "<br>"))
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatrix_Send(t *testing.T) {
+	var capturedReq *http.Request
+	var event map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r.Clone(r.Context())
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		_, _ = w.Write([]byte(`{"event_id":"$abc"}`))
+	}))
+	defer server.Close()
+
+	n, err := New("matrix", Options{Server: server.URL, Token: "syt_secret", Topic: "!room:example.org"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := n.Validate(); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title: "✅ Command Succeeded",
+		Body:  "make <all>\nCompleted in 5.0s",
+		Run:   &RunInfo{Command: "make <all>", Duration: 5 * time.Second},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if capturedReq.Method != "PUT" {
+		t.Errorf("method = %q, want PUT", capturedReq.Method)
+	}
+	prefix := "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/tn-"
+	if !strings.HasPrefix(capturedReq.URL.Path, prefix) {
+		t.Errorf("request path = %q, want prefix %q", capturedReq.URL.Path, prefix)
+	}
+	if got := capturedReq.Header.Get("Authorization"); got != "Bearer syt_secret" {
+		t.Errorf("Authorization header = %q, want %q", got, "Bearer syt_secret")
+	}
+	if event["msgtype"] != "m.text" || event["format"] != "org.matrix.custom.html" {
+		t.Errorf("event = %v, want an HTML m.text message", event)
+	}
+	if event["body"] != "✅ Command Succeeded\nmake <all>\nCompleted in 5.0s" {
+		t.Errorf("body = %q, want title and plain body", event["body"])
+	}
+	wantHTML := "<strong>✅ Command Succeeded</strong><br><pre><code>make &lt;all&gt;</code></pre>Completed in 5.0s"
+	if event["formatted_body"] != wantHTML {
+		t.Errorf("formatted_body = %q, want %q", event["formatted_body"], wantHTML)
+	}
+}
+
+func TestMatrix_ResolvesAlias(t *testing.T) {
+	var sentPath string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			if r.URL.Path != "/_matrix/client/v3/directory/room/#builds:example.org" {
+				t.Errorf("alias lookup path = %q", r.URL.Path)
+			}
+			_, _ = w.Write([]byte(`{"room_id":"!resolved:example.org"}`))
+			return
+		}
+		sentPath = r.URL.Path
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	n := &matrix{homeserver: server.URL, token: "tok", room: "#builds:example.org"}
+	if err := n.Send(context.Background(), &Message{Body: "hi"}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	if !strings.Contains(sentPath, "/rooms/!resolved:example.org/") {
+		t.Errorf("event sent to %q, want the resolved room ID", sentPath)
+	}
+}
+
+func TestMatrix_UniqueTxnIDs(t *testing.T) {
+	var paths []string
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if fail {
+			// The event may have been stored, but the response is lost.
+			fail = false
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	n := &matrix{homeserver: server.URL, token: "tok", room: "!r:x"}
+	if _, err := fastRetry.send(context.Background(), n, &Message{Body: "hi"}); err != nil {
+		t.Fatalf("send() returned unexpected error: %v", err)
+	}
+	if len(paths) != 2 || paths[0] != paths[1] {
+		t.Fatalf("paths = %q, want a retry with the same transaction ID", paths)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := n.Send(context.Background(), &Message{Body: "hi"}); err != nil {
+			t.Fatalf("Send() returned unexpected error: %v", err)
+		}
+	}
+	distinct := map[string]bool{}
+	for _, p := range paths {
+		distinct[p] = true
+	}
+	if len(distinct) != 3 {
+		t.Errorf("got %d distinct transaction paths for 3 messages, want 3", len(distinct))
+	}
+}
+
+func TestMatrix_Validate(t *testing.T) {
+	if err := (&matrix{token: "t", room: "!r:x"}).Validate(); err == nil {
+		t.Error("Validate() expected error for missing homeserver, got nil")
+	}
+	if err := (&matrix{homeserver: "h", room: "!r:x"}).Validate(); err == nil {
+		t.Error("Validate() expected error for missing token, got nil")
+	}
+	if err := (&matrix{homeserver: "h", token: "t", room: "room"}).Validate(); err == nil {
+		t.Error("Validate() expected error for malformed room, got nil")
+	}
+}
@@ -0,0 +1,168 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lee/term_notify/internal/mqtt"
+)
+
+func init() {
+	Register("mqtt", func(opts Options) (Notifier, error) {
+		m := &mqttNotifier{
+			topic:    opts.Topic,
+			user:     opts.User,
+			password: opts.Password,
+			clientID: opts.Params["client_id"],
+		}
+		if err := m.parseServer(opts.Server, opts.Params["tls"]); err != nil {
+			return nil, err
+		}
+		if q := opts.Params["qos"]; q != "" {
+			n, err := strconv.Atoi(q)
+			if err != nil || n < 0 || n > 2 {
+				return nil, fmt.Errorf("invalid MQTT qos %q (use 0, 1 or 2)", q)
+			}
+			m.qos = byte(n)
+		}
+		if r := opts.Params["retain"]; r != "" {
+			var err error
+			if m.retain, err = strconv.ParseBool(r); err != nil {
+				return nil, fmt.Errorf("invalid MQTT retain %q (use true or false)", r)
+			}
+		}
+		if m.clientID == "" {
+			m.clientID = fmt.Sprintf("tn-%d", os.Getpid())
+		}
+		return m, nil
+	})
+}
+
+// mqttNotifier publishes the run result as JSON to an MQTT 3.1.1 broker.
+// The broker comes from Server ("host[:port]" or an mqtt://, mqtts://,
+// tcp://, ssl:// or tls:// URL), the topic from Topic, credentials from
+// User/Password; QoS, retain, TLS and client ID are params.
+type mqttNotifier struct {
+	addr     string
+	useTLS   bool
+	topic    string
+	user     string
+	password string
+	clientID string
+	qos      byte
+	retain   bool
+
+	rootCAs *x509.CertPool // overrides the system roots in tests
+}
+
+// parseServer sets the broker address and TLS mode from the server setting
+// and the 'tls' param, which overrides the URL scheme when set.
+func (m *mqttNotifier) parseServer(server, tlsParam string) error {
+	host := server
+	if strings.Contains(server, "://") {
+		u, err := url.Parse(server)
+		if err != nil {
+			return fmt.Errorf("invalid MQTT broker URL: %w", err)
+		}
+		switch u.Scheme {
+		case "mqtt", "tcp":
+		case "mqtts", "ssl", "tls":
+			m.useTLS = true
+		default:
+			return fmt.Errorf("unsupported MQTT broker scheme %q (use mqtt or mqtts)", u.Scheme)
+		}
+		host = u.Host
+	}
+
+	if tlsParam != "" {
+		var err error
+		if m.useTLS, err = strconv.ParseBool(tlsParam); err != nil {
+			return fmt.Errorf("invalid MQTT tls %q (use true or false)", tlsParam)
+		}
+	}
+
+	if host != "" {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			port := "1883"
+			if m.useTLS {
+				port = "8883"
+			}
+			host = net.JoinHostPort(host, port)
+		}
+	}
+	m.addr = host
+	return nil
+}
+
+func (m *mqttNotifier) Name() string { return "mqtt" }
+
+// String returns the broker and topic, for status messages.
+func (m *mqttNotifier) String() string { return m.addr + "/" + m.topic }
+
+func (m *mqttNotifier) Validate() error {
+	if m.addr == "" {
+		return fmt.Errorf("MQTT broker is required — set it as the server (host:port or mqtt[s]://host)")
+	}
+	if m.topic == "" {
+		return fmt.Errorf("MQTT topic is required — set it as the topic")
+	}
+	if strings.ContainsAny(m.topic, "+#") {
+		return fmt.Errorf("MQTT topic %q must not contain wildcards", m.topic)
+	}
+	if m.password != "" && m.user == "" {
+		return fmt.Errorf("MQTT password needs a user — set the user as well")
+	}
+	return nil
+}
+
+func (m *mqttNotifier) Send(ctx context.Context, msg *Message) error {
+	payload, err := json.Marshal(newRunEvent(msg))
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	if m.useTLS {
+		host, _, _ := net.SplitHostPort(m.addr)
+		td := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host, RootCAs: m.rootCAs, MinVersion: tls.VersionTLS12}}
+		conn, err = td.DialContext(ctx, "tcp", m.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", m.addr)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to MQTT broker: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := mqtt.Connect(conn, mqtt.Options{
+		ClientID:  m.clientID,
+		Username:  m.user,
+		Password:  m.password,
+		KeepAlive: time.Minute,
+	})
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	if err := c.Publish(m.topic, payload, m.qos, m.retain); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	return c.Disconnect()
+}
@@ -0,0 +1,149 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lee/term_notify/internal/mqtt/mqtttest"
+)
+
+func waitBroker(t *testing.T, b *mqtttest.Broker) {
+	t.Helper()
+	select {
+	case <-b.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("MQTT session did not finish")
+	}
+}
+
+func TestMQTT_Send(t *testing.T) {
+	broker := mqtttest.Start(t, nil)
+
+	n, err := New("mqtt", Options{
+		Server:   "mqtt://" + broker.Addr,
+		Topic:    "home/tn/results",
+		User:     "tn",
+		Password: "s3cret",
+		Params:   map[string]string{"qos": "1", "retain": "true", "client_id": "laptop"},
+	})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := n.Validate(); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title:    "❌ Command Failed",
+		Body:     "make test",
+		Priority: "high",
+		Tags:     "x,ci",
+		Run:      &RunInfo{Command: "make test", ExitCode: 2, Duration: 1500 * time.Millisecond, Host: "box"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	waitBroker(t, broker)
+
+	clientID, user, pass := broker.Credentials()
+	if clientID != "laptop" || user != "tn" || pass != "s3cret" {
+		t.Errorf("CONNECT = (%q, %q, %q), want (laptop, tn, s3cret)", clientID, user, pass)
+	}
+
+	msgs := broker.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("broker received %d messages, want 1", len(msgs))
+	}
+	got := msgs[0]
+	if got.Topic != "home/tn/results" || got.QoS != 1 || !got.Retain {
+		t.Errorf("PUBLISH topic=%q qos=%d retain=%v, want home/tn/results 1 true", got.Topic, got.QoS, got.Retain)
+	}
+
+	var data runEvent
+	if err := json.Unmarshal(got.Payload, &data); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if data.Command != "make test" || data.ExitCode != 2 || data.Success || data.DurationMS != 1500 || data.Host != "box" {
+		t.Errorf("payload = %+v", data)
+	}
+	if len(data.Tags) != 2 || data.Tags[1] != "ci" {
+		t.Errorf("tags = %v, want [x ci]", data.Tags)
+	}
+}
+
+func TestMQTT_TLS(t *testing.T) {
+	serverTLS, roots := testTLS(t)
+	broker := mqtttest.Start(t, serverTLS)
+
+	n, err := New("mqtt", Options{Server: "mqtts://" + broker.Addr, Topic: "tn", Params: map[string]string{"qos": "2"}})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	n.(*mqttNotifier).rootCAs = roots
+
+	if err := n.Send(context.Background(), &Message{Title: "hi"}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	waitBroker(t, broker)
+
+	if msgs := broker.Messages(); len(msgs) != 1 || msgs[0].QoS != 2 {
+		t.Errorf("broker received %+v, want one QoS 2 message", msgs)
+	}
+}
+
+func TestMQTT_ConnectionRefused(t *testing.T) {
+	broker := mqtttest.Start(t, nil)
+	broker.Refuse(4)
+
+	n, _ := New("mqtt", Options{Server: broker.Addr, Topic: "tn", User: "tn", Password: "wrong"})
+	err := n.Send(context.Background(), &Message{Title: "hi"})
+	if err == nil {
+		t.Fatal("Send() expected error for refused connection, got nil")
+	}
+	if want := "bad user name or password"; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to mention %q", err, want)
+	}
+}
+
+func TestMQTT_Options(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		wantNew  bool // New should fail
+		wantErr  bool // Validate should fail
+		wantAddr string
+	}{
+		{"plain host", Options{Server: "broker.lan", Topic: "t"}, false, false, "broker.lan:1883"},
+		{"mqtts default port", Options{Server: "mqtts://broker.lan", Topic: "t"}, false, false, "broker.lan:8883"},
+		{"tls param", Options{Server: "broker.lan", Topic: "t", Params: map[string]string{"tls": "true"}}, false, false, "broker.lan:8883"},
+		{"explicit port", Options{Server: "tcp://broker.lan:1884", Topic: "t"}, false, false, "broker.lan:1884"},
+		{"bad scheme", Options{Server: "http://broker.lan", Topic: "t"}, true, false, ""},
+		{"bad qos", Options{Server: "broker.lan", Topic: "t", Params: map[string]string{"qos": "3"}}, true, false, ""},
+		{"bad retain", Options{Server: "broker.lan", Topic: "t", Params: map[string]string{"retain": "maybe"}}, true, false, ""},
+		{"missing server", Options{Topic: "t"}, false, true, ""},
+		{"missing topic", Options{Server: "broker.lan"}, false, true, "broker.lan:1883"},
+		{"wildcard topic", Options{Server: "broker.lan", Topic: "a/#"}, false, true, "broker.lan:1883"},
+		{"password without user", Options{Server: "broker.lan", Topic: "t", Password: "pw"}, false, true, "broker.lan:1883"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := New("mqtt", tt.opts)
+			if (err != nil) != tt.wantNew {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantNew)
+			}
+			if err != nil {
+				return
+			}
+			if err := n.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := n.(*mqttNotifier).addr; got != tt.wantAddr {
+				t.Errorf("addr = %q, want %q", got, tt.wantAddr)
+			}
+		})
+	}
+}
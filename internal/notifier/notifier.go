@@ -0,0 +1,160 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message represents a notification to be sent.
+//
+// Server, Topic and Token are only read by the package-level Send; backends
+// built with New take their connection settings from Options instead.
+type Message struct {
+	Server   string
+	Topic    string
+	Title    string
+	Body     string
+	Priority string
+	Tags     string
+	Token    string
+
+	// Click is a URL to open when the notification is tapped, Icon a URL
+	// of an image to show with it, and Attach the URL of a file to link
+	// as an attachment.
+	Click  string
+	Icon   string
+	Attach string
+	// Actions are buttons shown with the notification.
+	Actions []Action
+
+	// Delay asks the server to hold the message and deliver it this long
+	// after it is sent. Only backends that implement Scheduler honor it.
+	Delay time.Duration
+
+	// Run is set when the message reports a command run by tn, so
+	// backends with rich formatting can render it as structured fields.
+	Run *RunInfo
+
+	// Attachment is a local file to upload with the message, for backends
+	// that support it.
+	Attachment *Attachment
+}
+
+// Attachment is a file sent along with a message.
+type Attachment struct {
+	Name string `json:"name"` // file name shown to the recipient
+	Path string `json:"path"`
+}
+
+// RunInfo describes the outcome of a command run by tn.
+type RunInfo struct {
+	Command  string
+	ExitCode int
+	Duration time.Duration
+	Host     string
+	Cwd      string
+
+	// Output is the tail of the command's combined stdout and stderr, if
+	// it was captured.
+	Output string
+}
+
+// Succeeded reports whether the command exited with status 0.
+func (r *RunInfo) Succeeded() bool { return r.ExitCode == 0 }
+
+// Notifier delivers messages to a single notification backend.
+type Notifier interface {
+	// Name returns the backend identifier, e.g. "ntfy".
+	Name() string
+	// Validate reports whether the backend has enough configuration to send.
+	Validate() error
+	// Send delivers msg, giving up when ctx is done.
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Scheduler is implemented by backends whose server can hold a message
+// for later delivery.
+type Scheduler interface {
+	Notifier
+	// MaxDelay returns the longest Message.Delay the backend accepts.
+	MaxDelay() time.Duration
+}
+
+// Options holds the settings a backend is constructed from.
+type Options struct {
+	Server   string
+	Topic    string
+	Token    string
+	User     string
+	Password string
+
+	// Params holds backend-specific settings not covered above.
+	Params map[string]string
+	// Headers holds extra HTTP headers, for backends that accept them.
+	Headers map[string]string
+}
+
+// Factory constructs a Notifier from Options.
+type Factory func(opts Options) (Notifier, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a backend available under name. It panics if name is
+// already registered, since that can only be a programming error.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, dup := registry[name]; dup {
+		panic("notifier: Register called twice for backend " + name)
+	}
+	registry[name] = factory
+}
+
+// New constructs the backend registered under name, falling back to a
+// tn-notify-<name> plugin on PATH.
+func New(name string, opts Options) (Notifier, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		if p, found := lookupPlugin(name, opts); found {
+			return p, nil
+		}
+		available := append(Backends(), Plugins()...)
+		return nil, fmt.Errorf("unknown backend %q (available: %s; or install %s%s on PATH)",
+			name, strings.Join(available, ", "), PluginPrefix, name)
+	}
+	return factory(opts)
+}
+
+// Backends returns the names of all registered backends in sorted order.
+func Backends() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Send publishes a notification message to the ntfy server named in msg.
+// It predates the Notifier interface and is kept for existing callers.
+func Send(msg *Message) error {
+	n := newNtfy(Options{Server: msg.Server, Topic: msg.Topic, Token: msg.Token})
+	if err := n.Validate(); err != nil {
+		return err
+	}
+	return n.Send(context.Background(), msg)
+}
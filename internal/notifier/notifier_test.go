@@ -0,0 +1,79 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNew_UnknownBackend(t *testing.T) {
+	_, err := New("carrier-pigeon", Options{})
+	if err == nil {
+		t.Fatal("New() expected error for unknown backend, got nil")
+	}
+	if !strings.Contains(err.Error(), "ntfy") {
+		t.Errorf("error should list available backends, got %q", err.Error())
+	}
+}
+
+func TestBackends_IncludesNtfy(t *testing.T) {
+	for _, name := range Backends() {
+		if name == "ntfy" {
+			return
+		}
+	}
+	t.Errorf("Backends() = %v, want it to include %q", Backends(), "ntfy")
+}
+
+func TestRegister_DuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() should panic on duplicate backend name")
+		}
+	}()
+	Register("ntfy", func(Options) (Notifier, error) { return nil, nil })
+}
+
+func TestNew_Ntfy(t *testing.T) {
+	var capturedPath, capturedAuth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedPath = r.URL.Path
+		capturedAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n, err := New("ntfy", Options{Server: server.URL, Topic: "alerts", Token: "tk_abc"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if n.Name() != "ntfy" {
+		t.Errorf("Name() = %q, want %q", n.Name(), "ntfy")
+	}
+	if err := n.Validate(); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+
+	if err := n.Send(context.Background(), &Message{Body: "hi"}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	if capturedPath != "/alerts" {
+		t.Errorf("request path = %q, want %q", capturedPath, "/alerts")
+	}
+	if capturedAuth != "Bearer tk_abc" {
+		t.Errorf("Authorization header = %q, want %q", capturedAuth, "Bearer tk_abc")
+	}
+}
+
+func TestNew_NtfyValidateMissingTopic(t *testing.T) {
+	n, err := New("ntfy", Options{Server: "ntfy.sh"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := n.Validate(); err == nil {
+		t.Error("Validate() expected error for missing topic, got nil")
+	}
+}
@@ -1,49 +1,272 @@
 package notifier
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// Message represents a notification to be sent.
-type Message struct {
-	Server   string
-	Topic    string
-	Title    string
-	Body     string
-	Priority string
-	Tags     string
-	Token    string
+func init() {
+	Register("ntfy", func(opts Options) (Notifier, error) {
+		n := newNtfy(opts)
+		switch a := opts.Params["auth"]; a {
+		case "", "header":
+		case "query":
+			n.authQuery = true
+		default:
+			return nil, fmt.Errorf("invalid ntfy auth %q (use header or query)", a)
+		}
+		for param, dst := range map[string]*bool{"json": &n.json, "markdown": &n.markdown} {
+			v := opts.Params[param]
+			if v == "" {
+				continue
+			}
+			var err error
+			if *dst, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("invalid ntfy %s %q (use true or false)", param, v)
+			}
+		}
+		return n, nil
+	})
 }
 
-// Send publishes a notification message to the ntfy server.
-func Send(msg *Message) error {
-	if msg.Topic == "" {
-		return fmt.Errorf("topic is required — run 'tn config --topic <name>' or set TN_TOPIC")
-	}
+const (
+	// ntfyDefaultAttachmentLimit is ntfy's default per-file limit, used
+	// when the server does not report its own.
+	ntfyDefaultAttachmentLimit = 15 << 20
+	// ntfyFallbackTail is how much of an attachment is inlined in the
+	// message when the server refuses the upload.
+	ntfyFallbackTail = 2 << 10
+	// ntfyUploadTimeout bounds an attachment upload when the caller sets
+	// no deadline. Otherwise the upload ends ntfyInlineReserve before the
+	// deadline, leaving time to send the message without the attachment.
+	ntfyUploadTimeout = 5 * time.Minute
+	ntfyInlineReserve = 10 * time.Second
+	// ntfyMaxActions is the most action buttons ntfy accepts.
+	ntfyMaxActions = 3
+	// ntfyMaxDelay is how far ahead ntfy.sh, and ntfy by default, lets
+	// messages be scheduled.
+	ntfyMaxDelay = 3 * 24 * time.Hour
+)
 
-	server := msg.Server
+// ntfy publishes messages to an ntfy server over its HTTP API.
+type ntfy struct {
+	server   string
+	topic    string
+	token    string
+	user     string
+	password string
+	// authQuery sends credentials in the auth query parameter rather
+	// than the Authorization header, for proxies that strip the header.
+	authQuery bool
+	// json publishes through ntfy's JSON endpoint instead of headers, so
+	// non-ASCII titles and multi-line bodies reach the server untouched.
+	json bool
+	// markdown asks clients to render the body as Markdown, and adds the
+	// run's output to it as a code block.
+	markdown bool
+}
+
+func newNtfy(opts Options) *ntfy {
+	server := opts.Server
 	if server == "" {
 		server = "ntfy.sh"
 	}
 
-	// Ensure server has a scheme
-	if !strings.HasPrefix(server, "http://") && !strings.HasPrefix(server, "https://") {
-		server = "https://" + server
+	return &ntfy{
+		server:   baseURL(server),
+		topic:    opts.Topic,
+		token:    opts.Token,
+		user:     opts.User,
+		password: opts.Password,
+	}
+}
+
+func (n *ntfy) Name() string { return "ntfy" }
+
+// String returns the topic URL, for status messages.
+func (n *ntfy) String() string { return n.server + "/" + n.topic }
+
+// MaxDelay implements Scheduler.
+func (n *ntfy) MaxDelay() time.Duration { return ntfyMaxDelay }
+
+func (n *ntfy) Validate() error {
+	if n.topic == "" {
+		return fmt.Errorf("topic is required — run 'tn config --topic <name>' or set TN_TOPIC")
+	}
+	return nil
+}
+
+func (n *ntfy) Send(ctx context.Context, msg *Message) error {
+	if len(msg.Actions) > ntfyMaxActions {
+		return fmt.Errorf("ntfy allows at most %d actions, got %d", ntfyMaxActions, len(msg.Actions))
+	}
+	if msg.Attachment != nil {
+		return n.sendAttachment(ctx, msg)
 	}
+	if n.json {
+		return n.sendJSON(ctx, msg)
+	}
+
+	req, err := n.newRequest(ctx, "POST", msg, strings.NewReader(n.body(msg)))
+	if err != nil {
+		return err
+	}
+	_, err = do("ntfy", req)
+	return err
+}
+
+// ntfyPublish is the body of a request to ntfy's JSON publish endpoint.
+type ntfyPublish struct {
+	Topic    string   `json:"topic"`
+	Message  string   `json:"message,omitempty"`
+	Title    string   `json:"title,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Priority int      `json:"priority,omitempty"`
+	Click    string   `json:"click,omitempty"`
+	Icon     string   `json:"icon,omitempty"`
+	Attach   string   `json:"attach,omitempty"`
+	Actions  []Action `json:"actions,omitempty"`
+	Markdown bool     `json:"markdown,omitempty"`
+	Delay    string   `json:"delay,omitempty"`
+}
+
+// sendJSON publishes msg as a JSON document posted to the server root.
+func (n *ntfy) sendJSON(ctx context.Context, msg *Message) error {
+	priority, err := ntfyPriority(msg.Priority)
+	if err != nil {
+		return err
+	}
+
+	payload := ntfyPublish{
+		Topic:    n.topic,
+		Message:  n.body(msg),
+		Title:    msg.Title,
+		Priority: priority,
+		Click:    msg.Click,
+		Icon:     msg.Icon,
+		Attach:   msg.Attach,
+		Actions:  msg.Actions,
+		Markdown: n.markdown,
+	}
+	for _, tag := range strings.Split(msg.Tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			payload.Tags = append(payload.Tags, tag)
+		}
+	}
+	if msg.Delay > 0 {
+		payload.Delay = strconv.FormatInt(time.Now().Add(msg.Delay).Unix(), 10)
+	}
+
+	_, err = doJSON(ctx, "ntfy", "POST", n.endpoint(""), n.authHeader(), payload)
+	return err
+}
+
+// authorization returns the Authorization header value for the configured
+// credentials: the token if there is one, otherwise the user and password.
+func (n *ntfy) authorization() string {
+	switch {
+	case n.token != "":
+		return "Bearer " + n.token
+	case n.user != "":
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(n.user+":"+n.password))
+	}
+	return ""
+}
+
+// authHeader returns the headers that carry the credentials, if any.
+func (n *ntfy) authHeader() http.Header {
+	header := http.Header{}
+	if a := n.authorization(); a != "" && !n.authQuery {
+		header.Set("Authorization", a)
+	}
+	return header
+}
+
+// endpoint returns the URL of path on the server, with the credentials
+// in the auth query parameter when configured to send them there.
+func (n *ntfy) endpoint(path string) string {
+	url := n.server + path
+	if a := n.authorization(); a != "" && n.authQuery {
+		url += "?auth=" + base64.RawURLEncoding.EncodeToString([]byte(a))
+	}
+	return url
+}
+
+// ntfyPriority converts a priority name or number to the number the JSON
+// endpoint expects. The default priority is sent as 0, i.e. omitted.
+func ntfyPriority(p string) (int, error) {
+	switch p {
+	case "", "default", "3":
+		return 0, nil
+	case "min", "1":
+		return 1, nil
+	case "low", "2":
+		return 2, nil
+	case "high", "4":
+		return 4, nil
+	case "max", "urgent", "5":
+		return 5, nil
+	}
+	return 0, fmt.Errorf("invalid ntfy priority %q (use min, low, default, high or max)", p)
+}
+
+// body returns the message text to publish. In Markdown mode the run's
+// output, if captured, is appended as a code block.
+func (n *ntfy) body(msg *Message) string {
+	if !n.markdown || msg.Run == nil || msg.Run.Output == "" {
+		return msg.Body
+	}
+	output := strings.TrimRight(msg.Run.Output, "\n")
+	fence := codeFence(output)
+	return fmt.Sprintf("%s\n\n%s\n%s\n%s", msg.Body, fence, output, fence)
+}
+
+// codeFence returns a Markdown fence longer than any run of backticks in s,
+// so that s cannot close the code block early.
+func codeFence(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
 
-	url := fmt.Sprintf("%s/%s", strings.TrimRight(server, "/"), msg.Topic)
+// headerValue encodes v for a request header. In JSON mode, where the
+// caller asked for text to survive intact, non-ASCII values that must
+// still travel as headers are RFC 2047 encoded, which ntfy decodes.
+func (n *ntfy) headerValue(v string) string {
+	if !n.json {
+		return v
+	}
+	return mime.BEncoding.Encode("utf-8", v)
+}
 
-	req, err := http.NewRequest("POST", url, strings.NewReader(msg.Body))
+// newRequest builds a publish request carrying msg's metadata as headers.
+func (n *ntfy) newRequest(ctx context.Context, method string, msg *Message, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, n.endpoint("/"+n.topic), body)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
+	req.Header = n.authHeader()
 
 	if msg.Title != "" {
-		req.Header.Set("Title", msg.Title)
+		req.Header.Set("Title", n.headerValue(msg.Title))
 	}
 	if msg.Priority != "" && msg.Priority != "default" {
 		req.Header.Set("Priority", msg.Priority)
@@ -51,21 +274,191 @@ func Send(msg *Message) error {
 	if msg.Tags != "" {
 		req.Header.Set("Tags", msg.Tags)
 	}
-	if msg.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+msg.Token)
+	if msg.Click != "" {
+		req.Header.Set("Click", msg.Click)
+	}
+	if msg.Icon != "" {
+		req.Header.Set("Icon", msg.Icon)
+	}
+	// An uploaded file takes the place of an attachment URL.
+	if msg.Attach != "" && msg.Attachment == nil {
+		req.Header.Set("Attach", msg.Attach)
+	}
+	if msg.Delay > 0 {
+		// An absolute time keeps the schedule independent of the
+		// server's clock and time zone.
+		req.Header.Set("At", strconv.FormatInt(time.Now().Add(msg.Delay).Unix(), 10))
+	}
+	if n.markdown {
+		req.Header.Set("Markdown", "yes")
+	}
+	if len(msg.Actions) > 0 {
+		// ntfy accepts the JSON form of actions in the header, which
+		// avoids escaping labels and URLs for the short form.
+		actions, err := json.Marshal(msg.Actions)
+		if err != nil {
+			return nil, fmt.Errorf("encoding actions: %w", err)
+		}
+		req.Header.Set("Actions", string(actions))
 	}
+	return req, nil
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req) // #nosec G704 — URL is user-configured
+// sendAttachment uploads msg.Attachment as the request body, with the
+// message text moved into a header. A file larger than the server accepts
+// is cut down to its last bytes; if the server refuses the upload anyway,
+// or it does not finish in time, the message is sent without it and the
+// end of the file is inlined.
+func (n *ntfy) sendAttachment(ctx context.Context, msg *Message) error {
+	a := msg.Attachment
+	f, err := os.Open(a.Path) // #nosec G304 — attachment path is chosen by tn
 	if err != nil {
-		return fmt.Errorf("sending notification: %w", err)
+		return fmt.Errorf("opening attachment: %w", err)
 	}
-	defer resp.Body.Close() //nolint:errcheck
+	defer f.Close() //nolint:errcheck
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("ntfy server returned %d: %s", resp.StatusCode, string(body))
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("opening attachment: %w", err)
 	}
 
-	return nil
+	limit := n.attachmentLimit(ctx)
+	if limit <= 0 {
+		return n.sendInline(ctx, msg, f, info.Size())
+	}
+
+	body, offset := n.body(msg), int64(0)
+	if info.Size() > limit {
+		offset = info.Size() - limit
+		body += fmt.Sprintf("\n(attachment truncated to the last %s)", formatBytes(limit))
+	}
+
+	timeout := ntfyUploadTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline) - ntfyInlineReserve
+		if timeout <= 0 {
+			return n.sendInline(ctx, msg, f, info.Size())
+		}
+	}
+	uploadCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := n.newRequest(uploadCtx, "PUT", msg, io.NewSectionReader(f, offset, info.Size()-offset))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size() - offset
+	req.Header.Set("Filename", a.Name)
+	if body != "" {
+		// ntfy turns a literal \n in the Message header back into a newline.
+		req.Header.Set("Message", n.headerValue(strings.ReplaceAll(body, "\n", `\n`)))
+	}
+
+	_, err = doWith(uploadClient, "ntfy", req)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusRequestEntityTooLarge ||
+		httpErr.StatusCode == http.StatusBadRequest) {
+		return n.sendInline(ctx, msg, f, info.Size())
+	}
+	if err != nil && timedOut(err) && ctx.Err() == nil {
+		// Too slow to upload; retrying would only time out again.
+		return n.sendInline(ctx, msg, f, info.Size())
+	}
+	return err
+}
+
+// timedOut reports whether err is a deadline running out.
+func timedOut(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// sendInline sends msg without its attachment, appending the end of the
+// file to the body instead.
+func (n *ntfy) sendInline(ctx context.Context, msg *Message, f *os.File, size int64) error {
+	offset := max(size-ntfyFallbackTail, 0)
+	data, err := io.ReadAll(io.NewSectionReader(f, offset, size-offset))
+	if err != nil {
+		return fmt.Errorf("reading attachment: %w", err)
+	}
+	tail := string(data)
+	if offset > 0 {
+		// Drop the partial first line.
+		if i := strings.IndexByte(tail, '\n'); i >= 0 {
+			tail = tail[i+1:]
+		}
+	}
+
+	m := *msg
+	m.Attachment = nil
+	if tail = strings.TrimRight(tail, "\n"); tail != "" {
+		m.Body = strings.TrimRight(m.Body+"\n\n"+tail, "\n")
+	}
+	return n.Send(ctx, &m)
+}
+
+// attachmentLimit asks the server for the largest attachment this client
+// may upload. It returns 0 if the server has attachments turned off, and
+// ntfy's default if the server does not say.
+func (n *ntfy) attachmentLimit(ctx context.Context) int64 {
+	account, err := n.account(ctx)
+	if err != nil || account.Limits == nil {
+		return ntfyDefaultAttachmentLimit
+	}
+	return account.Limits.AttachmentFileSize
+}
+
+// ntfyAccount is the part of ntfy's /v1/account response tn uses.
+type ntfyAccount struct {
+	Username string `json:"username"`
+	Limits   *struct {
+		AttachmentFileSize int64 `json:"attachment_file_size"`
+	} `json:"limits"`
+}
+
+// account fetches the account the configured credentials belong to. ntfy
+// answers for the anonymous user "*" when there are no credentials.
+func (n *ntfy) account(ctx context.Context) (*ntfyAccount, error) {
+	body, err := doJSON(ctx, "ntfy", "GET", n.endpoint("/v1/account"), n.authHeader(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var account ntfyAccount
+	if err := json.Unmarshal(body, &account); err != nil {
+		return nil, fmt.Errorf("decoding account: %w", err)
+	}
+	return &account, nil
+}
+
+// NtfyLogin checks the credentials in opts against the ntfy server and
+// returns the name of the account they belong to.
+func NtfyLogin(ctx context.Context, opts Options) (string, error) {
+	if opts.Token == "" && opts.User == "" {
+		return "", fmt.Errorf("a token or a user is required")
+	}
+	account, err := newNtfy(opts).account(ctx)
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusUnauthorized ||
+		httpErr.StatusCode == http.StatusForbidden) {
+		return "", fmt.Errorf("the server rejected the credentials")
+	}
+	if err != nil {
+		return "", err
+	}
+	if account.Username == "" || account.Username == "*" {
+		// Servers without user accounts answer for everyone.
+		return "", fmt.Errorf("the server does not have user accounts enabled")
+	}
+	return account.Username, nil
+}
+
+// formatBytes renders n compactly, e.g. "512 B", "64 KB" or "15 MB".
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%d MB", n>>20)
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d B", n)
 }
@@ -1,10 +1,21 @@
 package notifier
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSend_Success(t *testing.T) {
@@ -175,3 +186,400 @@ func TestSend_EmptyServer(t *testing.T) {
 	// an empty URL. We don't assert success since this is an integration-level concern.
 	_ = Send(msg)
 }
+
+// ntfyAttachmentServer answers /v1/account with the given attachment limit
+// (or a 404 if negative) and records publish requests, rejecting uploads
+// with status if it is non-zero.
+func ntfyAttachmentServer(t *testing.T, limit int64, status int) (*httptest.Server, *[]*http.Request, *[]string) {
+	t.Helper()
+	var reqs []*http.Request
+	var bodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/account" {
+			if limit < 0 {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintf(w, `{"username":"*","limits":{"attachment_file_size":%d}}`, limit)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		reqs = append(reqs, r.Clone(r.Context()))
+		bodies = append(bodies, string(body))
+		if r.Method == "PUT" && status != 0 {
+			w.WriteHeader(status)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &reqs, &bodies
+}
+
+func writeLog(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "out.log")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNtfy_Attachment(t *testing.T) {
+	server, reqs, bodies := ntfyAttachmentServer(t, -1, 0)
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds"})
+
+	msg := &Message{
+		Title:      "✅ Command Succeeded",
+		Body:       "make\nCompleted in 1.0s",
+		Attachment: &Attachment{Name: "make.log", Path: writeLog(t, "line 1\nline 2\n")},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if len(*reqs) != 1 {
+		t.Fatalf("got %d publish requests, want 1", len(*reqs))
+	}
+	req := (*reqs)[0]
+	if req.Method != "PUT" || req.URL.Path != "/builds" {
+		t.Errorf("request = %s %s, want PUT /builds", req.Method, req.URL.Path)
+	}
+	if got := req.Header.Get("Filename"); got != "make.log" {
+		t.Errorf("Filename header = %q, want make.log", got)
+	}
+	if got := req.Header.Get("Message"); got != `make\nCompleted in 1.0s` {
+		t.Errorf("Message header = %q, want the body with escaped newlines", got)
+	}
+	if (*bodies)[0] != "line 1\nline 2\n" {
+		t.Errorf("uploaded body = %q, want the file contents", (*bodies)[0])
+	}
+}
+
+func TestNtfy_AttachmentTruncatedToServerLimit(t *testing.T) {
+	server, reqs, bodies := ntfyAttachmentServer(t, 1024, 0)
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds"})
+
+	content := strings.Repeat("x", 4096) + "THE END"
+	msg := &Message{Body: "train.sh", Attachment: &Attachment{Name: "train.log", Path: writeLog(t, content)}}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if got := (*bodies)[0]; len(got) != 1024 || !strings.HasSuffix(got, "THE END") {
+		t.Errorf("uploaded %d bytes ending %q, want the last 1024 bytes", len(got), got[len(got)-7:])
+	}
+	if got := (*reqs)[0].Header.Get("Message"); !strings.Contains(got, "truncated to the last 1 KB") {
+		t.Errorf("Message header = %q, want a truncation note", got)
+	}
+}
+
+func TestNtfy_AttachmentRejectedFallsBackToInline(t *testing.T) {
+	server, reqs, bodies := ntfyAttachmentServer(t, -1, http.StatusRequestEntityTooLarge)
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds"})
+
+	content := strings.Repeat("noise\n", 1000) + "error: disk full\n"
+	msg := &Message{Title: "❌ Command Failed", Body: "backup.sh", Attachment: &Attachment{Name: "backup.log", Path: writeLog(t, content)}}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if len(*reqs) != 2 || (*reqs)[1].Method != "POST" {
+		t.Fatalf("requests = %d, want a rejected PUT followed by a POST", len(*reqs))
+	}
+	body := (*bodies)[1]
+	if !strings.HasPrefix(body, "backup.sh\n\nnoise\n") || !strings.HasSuffix(body, "error: disk full") {
+		t.Errorf("fallback body = %q..., want the message followed by whole lines from the end of the log", body[:40])
+	}
+	if len(body) > ntfyFallbackTail+len("backup.sh\n\n") {
+		t.Errorf("fallback body is %d bytes, want at most the inline tail", len(body))
+	}
+}
+
+func TestNtfy_AttachmentUploadTimeoutFallsBackToInline(t *testing.T) {
+	var methods []string
+	var inline string
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/account" {
+			http.NotFound(w, r)
+			return
+		}
+		methods = append(methods, r.Method)
+		if r.Method == "PUT" {
+			// A link too slow to finish the upload.
+			<-stalled
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		inline = string(body)
+	}))
+	defer server.Close()
+	defer close(stalled)
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), ntfyInlineReserve+300*time.Millisecond)
+	defer cancel()
+	msg := &Message{Body: "backup.sh", Attachment: &Attachment{Name: "backup.log", Path: writeLog(t, "error: disk full\n")}}
+	if err := n.Send(ctx, msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	if strings.Join(methods, ",") != "PUT,POST" || inline != "backup.sh\n\nerror: disk full" {
+		t.Errorf("requests = %v with inline body %q, want a timed-out PUT followed by the tail", methods, inline)
+	}
+}
+
+func TestNtfy_AttachmentsDisabled(t *testing.T) {
+	server, reqs, _ := ntfyAttachmentServer(t, 0, 0)
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds"})
+
+	msg := &Message{Body: "job", Attachment: &Attachment{Name: "job.log", Path: writeLog(t, "done\n")}}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	if len(*reqs) != 1 || (*reqs)[0].Method != "POST" {
+		t.Errorf("want a single POST without upload when attachments are disabled")
+	}
+}
+
+func TestNtfy_LinksAndActions(t *testing.T) {
+	var captured http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds"})
+	msg := &Message{
+		Title:  "Build",
+		Click:  "https://ci.example.com/job/42",
+		Icon:   "https://ci.example.com/icon.png",
+		Attach: "https://ci.example.com/job/42/report.pdf",
+		Actions: []Action{
+			{Action: "view", Label: "Logs", URL: "https://ci.example.com/job/42/log"},
+			{Action: "http", Label: "Retry", URL: "https://ci.example.com/job/42/retry", Method: "POST", Clear: true},
+		},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	for header, want := range map[string]string{
+		"Click":  msg.Click,
+		"Icon":   msg.Icon,
+		"Attach": msg.Attach,
+	} {
+		if got := captured.Get(header); got != want {
+			t.Errorf("%s header = %q, want %q", header, got, want)
+		}
+	}
+
+	var actions []Action
+	if err := json.Unmarshal([]byte(captured.Get("Actions")), &actions); err != nil {
+		t.Fatalf("Actions header is not JSON: %v", err)
+	}
+	if !reflect.DeepEqual(actions, msg.Actions) {
+		t.Errorf("Actions = %+v, want %+v", actions, msg.Actions)
+	}
+}
+
+func TestNtfy_TooManyActions(t *testing.T) {
+	n, _ := New("ntfy", Options{Server: "http://127.0.0.1:1", Topic: "builds"})
+	a := Action{Action: "view", Label: "x", URL: "https://x"}
+	if err := n.Send(context.Background(), &Message{Actions: []Action{a, a, a, a}}); err == nil {
+		t.Error("Send() expected error for four actions, got nil")
+	}
+}
+
+func TestNtfy_Delay(t *testing.T) {
+	var at string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		at = r.Header.Get("At")
+	}))
+	defer server.Close()
+
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "reminders"})
+	before := time.Now()
+	if err := n.Send(context.Background(), &Message{Body: "stretch", Delay: time.Hour}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	unix, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		t.Fatalf("At header = %q, want a unix timestamp", at)
+	}
+	if want := before.Add(time.Hour).Unix(); unix < want || unix > want+5 {
+		t.Errorf("At = %d, want about %d", unix, want)
+	}
+}
+
+func TestNtfy_JSONPublish(t *testing.T) {
+	var path, contentType string
+	var got ntfyPublish
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	n, err := New("ntfy", Options{Server: server.URL, Topic: "builds", Params: map[string]string{"json": "true"}})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	msg := &Message{
+		Title:    "✅ Command Succeeded",
+		Body:     "make\nCompleted in 3s",
+		Priority: "high",
+		Tags:     "white_check_mark, ci",
+		Actions:  []Action{{Action: "view", Label: "Logs", URL: "https://ci.example.com"}},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if path != "/" || contentType != "application/json" {
+		t.Errorf("request = %s (%s), want JSON posted to /", path, contentType)
+	}
+	want := ntfyPublish{
+		Topic:    "builds",
+		Title:    msg.Title,
+		Message:  msg.Body,
+		Priority: 4,
+		Tags:     []string{"white_check_mark", "ci"},
+		Actions:  msg.Actions,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+}
+
+func TestNtfy_JSONInvalidPriority(t *testing.T) {
+	n, _ := New("ntfy", Options{Server: "http://127.0.0.1:1", Topic: "builds", Params: map[string]string{"json": "true"}})
+	if err := n.Send(context.Background(), &Message{Priority: "loud"}); err == nil {
+		t.Error("Send() expected error for unknown priority, got nil")
+	}
+}
+
+func TestNtfy_InvalidParam(t *testing.T) {
+	if _, err := New("ntfy", Options{Topic: "builds", Params: map[string]string{"markdown": "sure"}}); err == nil {
+		t.Error("New() expected error for invalid markdown param, got nil")
+	}
+}
+
+func TestNtfy_MarkdownOutput(t *testing.T) {
+	var markdown, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		markdown = r.Header.Get("Markdown")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds", Params: map[string]string{"markdown": "true"}})
+	msg := &Message{
+		Body: "make test\nFailed in 3s (exit code 2)",
+		Run:  &RunInfo{Command: "make test", ExitCode: 2, Output: "--- FAIL: TestX\n```go\nx := 1\n```\n"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if markdown != "yes" {
+		t.Errorf("Markdown header = %q, want %q", markdown, "yes")
+	}
+	want := msg.Body + "\n\n````\n--- FAIL: TestX\n```go\nx := 1\n```\n````"
+	if body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestNtfy_JSONAttachmentEncodesHeaders(t *testing.T) {
+	server, reqs, _ := ntfyAttachmentServer(t, 1<<20, http.StatusOK)
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds", Params: map[string]string{"json": "true"}})
+
+	msg := &Message{
+		Title:      "❌ Command Failed",
+		Body:       "make",
+		Attachment: &Attachment{Name: "make.log", Path: writeLog(t, "boom\n")},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	title := (*reqs)[len(*reqs)-1].Header.Get("Title")
+	decoded, err := new(mime.WordDecoder).DecodeHeader(title)
+	if err != nil || decoded != msg.Title || title == msg.Title {
+		t.Errorf("Title header = %q, want %q RFC 2047 encoded", title, msg.Title)
+	}
+}
+
+func TestNtfy_BasicAuth(t *testing.T) {
+	var user, password string
+	var ok bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok = r.BasicAuth()
+	}))
+	defer server.Close()
+
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds", User: "alice", Password: "s3cret"})
+	if err := n.Send(context.Background(), &Message{Body: "done"}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	if !ok || user != "alice" || password != "s3cret" {
+		t.Errorf("basic auth = %q/%q (%v), want alice/s3cret", user, password, ok)
+	}
+}
+
+func TestNtfy_QueryAuth(t *testing.T) {
+	var auth, header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, header = r.URL.Query().Get("auth"), r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	n, _ := New("ntfy", Options{
+		Server: server.URL, Topic: "builds", User: "alice", Password: "s3cret",
+		Params: map[string]string{"auth": "query", "json": "true"},
+	})
+	if err := n.Send(context.Background(), &Message{Body: "done"}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if header != "" {
+		t.Errorf("Authorization header = %q, want none with query auth", header)
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(auth)
+	if err != nil {
+		t.Fatalf("auth = %q, want unpadded base64: %v", auth, err)
+	}
+	if want := "Basic YWxpY2U6czNjcmV0"; string(decoded) != want {
+		t.Errorf("decoded auth = %q, want %q", decoded, want)
+	}
+}
+
+func TestNtfyLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		switch {
+		case !ok:
+			fmt.Fprint(w, `{"username":"*"}`)
+		case user == "alice" && password == "s3cret":
+			fmt.Fprint(w, `{"username":"alice"}`)
+		default:
+			http.Error(w, `{"code":40101,"http":401,"error":"unauthorized"}`, http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	name, err := NtfyLogin(context.Background(), Options{Server: server.URL, User: "alice", Password: "s3cret"})
+	if err != nil || name != "alice" {
+		t.Errorf("NtfyLogin() = %q, %v, want alice", name, err)
+	}
+	if _, err := NtfyLogin(context.Background(), Options{Server: server.URL, User: "alice", Password: "wrong"}); err == nil {
+		t.Error("NtfyLogin() expected error for a wrong password, got nil")
+	}
+	if _, err := NtfyLogin(context.Background(), Options{Server: server.URL}); err == nil {
+		t.Error("NtfyLogin() expected error without credentials, got nil")
+	}
+}
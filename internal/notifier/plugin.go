@@ -0,0 +1,208 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PluginPrefix is the executable name prefix for external backends: a
+// backend named "foo" that is not built in runs tn-notify-foo from PATH.
+const PluginPrefix = "tn-notify-"
+
+// pluginProtocol is the version of the stdin/stdout protocol below; plugins
+// should reject versions they do not understand.
+const pluginProtocol = 1
+
+// plugin delivers messages by running an external executable. The request
+// is written to its stdin as a single JSON object and a pluginStatus is
+// read back from stdout.
+type plugin struct {
+	name string
+	path string
+	opts Options
+}
+
+type pluginRequest struct {
+	Version int            `json:"version"`
+	Backend string         `json:"backend"`
+	Message pluginMessage  `json:"message"`
+	Options pluginSettings `json:"options"`
+}
+
+type pluginMessage struct {
+	Title    string     `json:"title"`
+	Body     string     `json:"body"`
+	Priority string     `json:"priority"`
+	Tags     []string   `json:"tags"`
+	Run      *pluginRun `json:"run,omitempty"`
+
+	Click   string   `json:"click,omitempty"`
+	Icon    string   `json:"icon,omitempty"`
+	Attach  string   `json:"attach,omitempty"`
+	Actions []Action `json:"actions,omitempty"`
+
+	// Attachment is the path of a local file to send with the message.
+	Attachment *Attachment `json:"attachment,omitempty"`
+}
+
+type pluginRun struct {
+	Command    string `json:"command"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMS int64  `json:"duration_ms"`
+	Host       string `json:"host"`
+	Cwd        string `json:"cwd"`
+	Output     string `json:"output,omitempty"`
+}
+
+type pluginSettings struct {
+	Server   string            `json:"server,omitempty"`
+	Topic    string            `json:"topic,omitempty"`
+	Token    string            `json:"token,omitempty"`
+	User     string            `json:"user,omitempty"`
+	Password string            `json:"password,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// pluginStatus is what a plugin prints on stdout when it is done.
+type pluginStatus struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// lookupPlugin returns a plugin backend for name if tn-notify-<name> is
+// on PATH.
+func lookupPlugin(name string, opts Options) (Notifier, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, false
+	}
+	path, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		return nil, false
+	}
+	return &plugin{name: name, path: path, opts: opts}, true
+}
+
+// Plugins returns the names of the plugin backends found on PATH, in
+// sorted order and without the tn-notify- prefix.
+func Plugins() []string {
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), PluginPrefix)
+			if !ok || name == "" || e.IsDir() {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (p *plugin) Name() string { return p.name }
+
+// String returns the executable path, for status messages.
+func (p *plugin) String() string { return p.path }
+
+// Validate has nothing to check beyond the executable having been found;
+// plugins report their own configuration errors from Send.
+func (p *plugin) Validate() error { return nil }
+
+func (p *plugin) Send(ctx context.Context, msg *Message) error {
+	req := pluginRequest{
+		Version: pluginProtocol,
+		Backend: p.name,
+		Message: pluginMessage{
+			Title:    msg.Title,
+			Body:     msg.Body,
+			Priority: msg.Priority,
+			Tags:     newRunEvent(msg).Tags,
+
+			Click:      msg.Click,
+			Icon:       msg.Icon,
+			Attach:     msg.Attach,
+			Actions:    msg.Actions,
+			Attachment: msg.Attachment,
+		},
+		Options: pluginSettings{
+			Server:   p.opts.Server,
+			Topic:    p.opts.Topic,
+			Token:    p.opts.Token,
+			User:     p.opts.User,
+			Password: p.opts.Password,
+			Params:   p.opts.Params,
+			Headers:  p.opts.Headers,
+		},
+	}
+	if run := msg.Run; run != nil {
+		req.Message.Run = &pluginRun{
+			Command:    run.Command,
+			ExitCode:   run.ExitCode,
+			DurationMS: run.Duration.Milliseconds(),
+			Host:       run.Host,
+			Cwd:        run.Cwd,
+			Output:     run.Output,
+		}
+	}
+
+	input, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encoding plugin request: %w", err)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path) // #nosec G204 — plugin path comes from PATH lookup
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	var status pluginStatus
+	decodeErr := json.Unmarshal(bytes.TrimSpace(stdout.Bytes()), &status)
+
+	switch {
+	case decodeErr == nil && !status.OK:
+		if status.Error == "" {
+			status.Error = "reported failure"
+		}
+		return fmt.Errorf("%s%s: %s", PluginPrefix, p.name, status.Error)
+	case runErr != nil:
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) && stderr.Len() > 0 {
+			return fmt.Errorf("%s%s: %s", PluginPrefix, p.name, strings.TrimSpace(stderr.String()))
+		}
+		return fmt.Errorf("%s%s: %w", PluginPrefix, p.name, runErr)
+	case decodeErr != nil:
+		return fmt.Errorf("%s%s: invalid status on stdout: %w", PluginPrefix, p.name, decodeErr)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// installPlugin writes a tn-notify-<name> shell script into a temp dir and
+// puts that dir first on PATH.
+func installPlugin(t *testing.T, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin tests use shell scripts")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, PluginPrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil { // #nosec G306 — must be executable
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestPlugin_Send(t *testing.T) {
+	dir := installPlugin(t, "acme", `cat > "$(dirname "$0")/request.json"; echo '{"ok": true}'`)
+
+	n, err := New("acme", Options{Server: "chat.acme.internal", Token: "t0k", Params: map[string]string{"room": "ops"}})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if n.Name() != "acme" {
+		t.Errorf("Name() = %q, want acme", n.Name())
+	}
+
+	msg := &Message{
+		Title:    "✅ Command Succeeded",
+		Body:     "make",
+		Priority: "low",
+		Tags:     "a, b",
+		Run:      &RunInfo{Command: "make", Duration: 2 * time.Second, Host: "box"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "request.json")) // #nosec G304 — test temp dir
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req pluginRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatalf("plugin received invalid JSON: %v", err)
+	}
+	if req.Version != 1 || req.Backend != "acme" {
+		t.Errorf("version/backend = %d/%q, want 1/acme", req.Version, req.Backend)
+	}
+	if req.Message.Title != msg.Title || req.Message.Priority != "low" || len(req.Message.Tags) != 2 {
+		t.Errorf("message = %+v", req.Message)
+	}
+	if req.Message.Run == nil || req.Message.Run.DurationMS != 2000 || req.Message.Run.Host != "box" {
+		t.Errorf("run = %+v", req.Message.Run)
+	}
+	if req.Options.Server != "chat.acme.internal" || req.Options.Token != "t0k" || req.Options.Params["room"] != "ops" {
+		t.Errorf("options = %+v", req.Options)
+	}
+}
+
+func TestPlugin_Failures(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"reported error", `echo '{"ok": false, "error": "room not found"}'`, "room not found"},
+		{"exit status", `echo "token expired" >&2; exit 3`, "token expired"},
+		{"no status", `cat > /dev/null`, "invalid status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installPlugin(t, "acme", tt.script)
+
+			n, err := New("acme", Options{})
+			if err != nil {
+				t.Fatalf("New() returned unexpected error: %v", err)
+			}
+			err = n.Send(context.Background(), &Message{Title: "hi"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Send() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	installPlugin(t, "zeta", `echo '{"ok": true}'`)
+	dir := filepath.SplitList(os.Getenv("PATH"))[0]
+	if err := os.WriteFile(filepath.Join(dir, PluginPrefix+"alpha"), []byte("#!/bin/sh\n"), 0o755); err != nil { // #nosec G306 — must be executable
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(Plugins(), ","); got != "alpha,zeta" {
+		t.Errorf("Plugins() = %q, want alpha,zeta", got)
+	}
+
+	_, err := New("missing", Options{})
+	if err == nil || !strings.Contains(err.Error(), "tn-notify-missing") {
+		t.Errorf("New() error = %v, want it to suggest tn-notify-missing", err)
+	}
+}
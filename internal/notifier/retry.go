@@ -0,0 +1,149 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how often a failed delivery is retried. The zero
+// value makes a single attempt.
+type RetryPolicy struct {
+	// Attempts is the most times a message is sent, including the first.
+	Attempts int
+	// Deadline bounds the time spent on all attempts together, including
+	// the waits between them. Zero means no limit beyond Attempts.
+	Deadline time.Duration
+	// BaseDelay is the wait before the first retry; it doubles for each
+	// retry after that, up to MaxDelay, and is jittered.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetry is the policy tn uses unless configured otherwise.
+var DefaultRetry = RetryPolicy{
+	Attempts:  4,
+	Deadline:  time.Minute,
+	BaseDelay: time.Second,
+	MaxDelay:  15 * time.Second,
+}
+
+// send delivers msg with n, retrying transient failures as p allows. It
+// returns the number of attempts made along with the last error.
+func (p RetryPolicy) send(ctx context.Context, n Notifier, msg *Message) (int, error) {
+	if p.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Deadline)
+		defer cancel()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := n.Send(ctx, msg)
+		if err == nil || attempt >= p.Attempts || !Retryable(err) {
+			return attempt, err
+		}
+
+		wait := p.backoff(attempt, err)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			// The next attempt could not start in time.
+			return attempt, err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns how long to wait after the given attempt failed with err.
+// A wait the server asked for takes precedence over the exponential
+// schedule.
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		return httpErr.RetryAfter
+	}
+
+	d := p.BaseDelay << (attempt - 1)
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d < p.BaseDelay) {
+		// Capped, or doubled so often it overflowed.
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Keep at least half the delay so retries from many clients still
+	// spread out rather than collapsing to zero.
+	return d/2 + rand.N(d/2+1) // #nosec G404 — jitter needs no crypto
+}
+
+// Retryable reports whether err is worth another attempt: a network
+// failure, or a server that is overloaded or briefly unavailable. Errors
+// that another attempt would only repeat, such as a certificate that does
+// not verify or a malformed URL, are not.
+func Retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	}
+	if certificateError(err) {
+		return false
+	}
+
+	// *url.Error satisfies net.Error whatever it wraps, so look for the
+	// failures of the network itself rather than for net.Error.
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &opErr), errors.As(err, &dnsErr):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		// The server closed the connection without answering.
+		return true
+	}
+	return false
+}
+
+// certificateError reports whether err comes from a TLS handshake that
+// failed on the server's certificate or did not speak TLS at all.
+func certificateError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	return errors.As(err, &verifyErr) || errors.As(err, &recordErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr)
+}
+
+// retryAfter parses a Retry-After header, given either as seconds or as an
+// HTTP date.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
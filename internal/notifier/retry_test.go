@@ -0,0 +1,160 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fastRetry = RetryPolicy{Attempts: 4, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond}
+
+func TestRetry_TransientServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds"})
+	results := Dispatch(context.Background(), []Destination{{Name: "phone", Notifier: n, Retry: fastRetry}}, &Message{Body: "done"})
+
+	if results[0].Err != nil || results[0].Attempts != 3 {
+		t.Errorf("result = %+v, want success on attempt 3", results[0])
+	}
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		attempts int
+	}{
+		{"client error is not retried", http.StatusBadRequest, 1},
+		{"rate limit is retried", http.StatusTooManyRequests, 4},
+		{"server error is retried", http.StatusBadGateway, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds"})
+			attempts, err := fastRetry.send(context.Background(), n, &Message{Body: "done"})
+			if err == nil || attempts != tt.attempts || int(calls.Load()) != tt.attempts {
+				t.Errorf("send() = %d attempts (%d calls), %v; want %d failed attempts", attempts, calls.Load(), err, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestRetry_Deadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	n, _ := New("ntfy", Options{Server: server.URL, Topic: "builds"})
+	p := fastRetry
+	p.Deadline = time.Second
+
+	start := time.Now()
+	attempts, err := p.send(context.Background(), n, &Message{Body: "done"})
+	if err == nil || attempts != 1 {
+		t.Errorf("send() = %d attempts, %v; want to stop after 1 when Retry-After passes the deadline", attempts, err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("send() took %s, want it to give up without waiting", elapsed)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&HTTPError{StatusCode: 500}, true},
+		{&HTTPError{StatusCode: 429}, true},
+		{&HTTPError{StatusCode: 404}, false},
+		{fmt.Errorf("sending notification: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), true},
+		{&url.Error{Op: "Post", URL: "https://ntfy.sh/x", Err: &net.DNSError{Err: "no such host", Name: "ntfy.sh"}}, true},
+		{&url.Error{Op: "Post", URL: "https://ntfy.sh/x", Err: io.EOF}, true},
+		{&url.Error{Op: "Post", URL: "https://ntfy.sh/x", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}}, false},
+		{&url.Error{Op: "Post", URL: "https://ntfy.sh/x", Err: x509.HostnameError{Host: "ntfy.sh"}}, false},
+		{&url.Error{Op: "Post", URL: "ftp://ntfy.sh/x", Err: errors.New(`unsupported protocol scheme "ftp"`)}, false},
+		{context.Canceled, false},
+		{errors.New("topic is required"), false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.want {
+			t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryable_ClientErrors(t *testing.T) {
+	// The server's certificate is not trusted by the default client.
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	for _, target := range []string{server.URL, "ftp://ntfy.sh/x", "https://ntfy .sh/x"} {
+		n, _ := New("webhook", Options{Server: target})
+		err := n.Send(context.Background(), &Message{Body: "done"})
+		if err == nil || Retryable(err) {
+			t.Errorf("Send() to %s = %v, want an error that is not retried", target, err)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for attempt, limit := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 70: 5 * time.Second} {
+		if d := p.backoff(attempt, errors.New("x")); d < limit/2 || d > limit {
+			t.Errorf("backoff(%d) = %s, want between %s and %s", attempt, d, limit/2, limit)
+		}
+	}
+
+	if d := p.backoff(1, &HTTPError{StatusCode: 429, RetryAfter: 7 * time.Second}); d != 7*time.Second {
+		t.Errorf("backoff() with RetryAfter = %s, want 7s", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(http.Header{"Retry-After": {tt.value}}, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %s, %v; want %s, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
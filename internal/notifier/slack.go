@@ -0,0 +1,99 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	// Mattermost and Rocket.Chat accept Slack's incoming-webhook format.
+	for _, name := range []string{"slack", "mattermost", "rocketchat"} {
+		Register(name, func(opts Options) (Notifier, error) {
+			return &slack{name: name, webhook: opts.Server}, nil
+		})
+	}
+}
+
+// slack posts messages to a Slack-compatible incoming webhook.
+type slack struct {
+	name    string
+	webhook string
+}
+
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Fallback string       `json:"fallback"`
+	Color    string       `json:"color,omitempty"`
+	Title    string       `json:"title,omitempty"`
+	Text     string       `json:"text,omitempty"`
+	Fields   []slackField `json:"fields,omitempty"`
+	MrkdwnIn []string     `json:"mrkdwn_in,omitempty"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func (s *slack) Name() string { return s.name }
+
+// String returns the webhook host; the full URL embeds a secret.
+func (s *slack) String() string {
+	if u, err := url.Parse(s.webhook); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return s.name
+}
+
+func (s *slack) Validate() error {
+	if s.webhook == "" {
+		return fmt.Errorf("%s webhook URL is required — set it as the server", s.name)
+	}
+	return nil
+}
+
+func (s *slack) Send(ctx context.Context, msg *Message) error {
+	_, err := doJSON(ctx, s.name, "POST", s.webhook, nil, slackMessage(msg))
+	return err
+}
+
+// slackMessage renders msg as a webhook payload. Command results get a
+// colored sidebar and one field per detail instead of the plain-text body.
+func slackMessage(msg *Message) *slackPayload {
+	attachment := slackAttachment{
+		Fallback: msg.Title,
+		Title:    msg.Title,
+		Text:     slackEscape(msg.Body),
+	}
+
+	if run := msg.Run; run != nil {
+		attachment.Text = "```" + slackEscape(run.Command) + "```"
+		attachment.MrkdwnIn = []string{"text"}
+		attachment.Fields = []slackField{
+			{Title: "Duration", Value: FormatDuration(run.Duration), Short: true},
+			{Title: "Exit code", Value: strconv.Itoa(run.ExitCode), Short: true},
+		}
+		attachment.Color = hexColor(colorSuccess)
+		if !run.Succeeded() {
+			attachment.Color = hexColor(colorFailure)
+		}
+	}
+
+	return &slackPayload{
+		Text:        slackEscape(msg.Title),
+		Attachments: []slackAttachment{attachment},
+	}
+}
+
+// slackEscape escapes the characters Slack treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
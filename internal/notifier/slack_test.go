@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSlack_SendRunResult(t *testing.T) {
+	var payload slackPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	n, err := New("slack", Options{Server: server.URL + "/services/T000/B000/XXX"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title: "❌ Command Failed",
+		Body:  "make test\nFailed in 2.0s (exit code 2)",
+		Run:   &RunInfo{Command: "make test && echo <done>", ExitCode: 2, Duration: 2 * time.Second},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if payload.Text != "❌ Command Failed" {
+		t.Errorf("text = %q, want the title", payload.Text)
+	}
+	if len(payload.Attachments) != 1 {
+		t.Fatalf("len(attachments) = %d, want 1", len(payload.Attachments))
+	}
+	a := payload.Attachments[0]
+	if a.Color != "#a30200" {
+		t.Errorf("color = %q, want %q", a.Color, "#a30200")
+	}
+	if a.Text != "```make test &amp;&amp; echo &lt;done&gt;```" {
+		t.Errorf("text = %q, want escaped command in a code block", a.Text)
+	}
+	if len(a.Fields) != 2 || a.Fields[0].Value != "2.0s" || a.Fields[1].Value != "2" {
+		t.Errorf("fields = %+v, want duration and exit code", a.Fields)
+	}
+}
+
+func TestSlackMessage_Success(t *testing.T) {
+	p := slackMessage(&Message{Title: "ok", Run: &RunInfo{Command: "true"}})
+	if got := p.Attachments[0].Color; got != "#2eb886" {
+		t.Errorf("color = %q, want %q", got, "#2eb886")
+	}
+}
+
+func TestSlackMessage_PlainNotification(t *testing.T) {
+	p := slackMessage(&Message{Title: "📢 term_notify", Body: "Build complete!"})
+	a := p.Attachments[0]
+	if a.Color != "" || len(a.Fields) != 0 {
+		t.Errorf("plain notification should have no color or fields, got %+v", a)
+	}
+	if a.Text != "Build complete!" {
+		t.Errorf("text = %q, want the body", a.Text)
+	}
+}
+
+func TestSlack_Aliases(t *testing.T) {
+	for _, name := range []string{"mattermost", "rocketchat"} {
+		n, err := New(name, Options{Server: "https://chat.example.com/hooks/abc"})
+		if err != nil {
+			t.Fatalf("New(%q) returned unexpected error: %v", name, err)
+		}
+		if n.Name() != name {
+			t.Errorf("Name() = %q, want %q", n.Name(), name)
+		}
+		if s := n.(*slack).String(); s != "chat.example.com" {
+			t.Errorf("String() = %q, want host only", s)
+		}
+	}
+}
+
+func TestSlack_ValidateMissingWebhook(t *testing.T) {
+	n, _ := New("slack", Options{})
+	if err := n.Validate(); err == nil {
+		t.Error("Validate() expected error for missing webhook URL, got nil")
+	}
+}
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register("syslog", func(opts Options) (Notifier, error) {
+		s := &syslog{
+			network:  opts.Params["network"],
+			address:  opts.Params["address"],
+			facility: opts.Params["facility"],
+		}
+		if s.network == "" {
+			s.network = "unixgram"
+		}
+		if s.address == "" && s.network == "unixgram" {
+			s.address = "/dev/log"
+		}
+		if s.facility == "" {
+			s.facility = "user"
+		}
+		return s, nil
+	})
+}
+
+// sdID is the RFC 5424 structured-data ID for tn's fields. 32473 is the
+// enterprise number IANA reserves for documentation and examples.
+const sdID = "tn@32473"
+
+// syslogFacilities maps facility names to their RFC 5424 codes.
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslog writes RFC 5424 messages to a local socket or a UDP/TCP collector.
+type syslog struct {
+	network  string // unixgram, unix, udp or tcp
+	address  string
+	facility string
+}
+
+func (s *syslog) Name() string { return "syslog" }
+
+// String returns the collector address, for status messages.
+func (s *syslog) String() string { return s.network + ":" + s.address }
+
+func (s *syslog) Validate() error {
+	switch s.network {
+	case "unixgram", "unix", "udp", "tcp":
+	default:
+		return fmt.Errorf("unsupported syslog network %q (use unixgram, unix, udp or tcp)", s.network)
+	}
+	if s.address == "" {
+		return fmt.Errorf("syslog address is required for network %s", s.network)
+	}
+	if _, ok := syslogFacilities[s.facility]; !ok {
+		return fmt.Errorf("unknown syslog facility %q", s.facility)
+	}
+	return nil
+}
+
+func (s *syslog) Send(ctx context.Context, msg *Message) error {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return fmt.Errorf("connecting to syslog: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	line := s.format(msg, time.Now())
+	if s.network == "tcp" || s.network == "unix" {
+		// Stream transports need framing; use RFC 6587 octet counting.
+		line = strconv.Itoa(len(line)) + " " + line
+	}
+
+	if _, err := conn.Write([]byte(line)); err != nil {
+		return fmt.Errorf("writing to syslog: %w", err)
+	}
+	return nil
+}
+
+// format renders msg as an RFC 5424 message.
+func (s *syslog) format(msg *Message, now time.Time) string {
+	pri := syslogFacilities[s.facility]*8 + severity(msg)
+
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "-"
+	}
+
+	sd := "-"
+	if run := msg.Run; run != nil {
+		sd = fmt.Sprintf(`[%s command="%s" exit_code="%d" duration_ms="%d"]`,
+			sdID, escapeSDParam(run.Command), run.ExitCode, run.Duration.Milliseconds())
+	}
+
+	text := msg.Title
+	if msg.Body != "" {
+		text = strings.TrimPrefix(text+": "+msg.Body, ": ")
+	}
+	text = strings.ReplaceAll(text, "\n", " ")
+
+	return fmt.Sprintf("<%d>1 %s %s tn %d - %s \ufeff%s",
+		pri, now.Format(time.RFC3339Nano), host, os.Getpid(), sd, text)
+}
+
+// severity maps a message onto a syslog severity: failed commands are
+// errors, otherwise the ntfy-style priority decides.
+func severity(msg *Message) int {
+	if msg.Run != nil && !msg.Run.Succeeded() {
+		return 3 // err
+	}
+	switch msg.Priority {
+	case "max", "urgent", "5":
+		return 2 // crit
+	case "high", "4":
+		return 4 // warning
+	case "low", "min", "2", "1":
+		return 6 // info
+	}
+	return 5 // notice
+}
+
+// escapeSDParam escapes the characters RFC 5424 reserves in SD-PARAM values.
+func escapeSDParam(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,162 @@
+package notifier
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenUnixgram opens a datagram socket in a temp dir and returns its path
+// and a function that reads the next datagram.
+func listenUnixgram(t *testing.T) (string, func() string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return path, func() string {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		buf := make([]byte, 64*1024)
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("reading datagram: %v", err)
+		}
+		return string(buf[:n])
+	}
+}
+
+func TestSyslog_Unixgram(t *testing.T) {
+	path, read := listenUnixgram(t)
+
+	n, err := New("syslog", Options{Params: map[string]string{"address": path, "facility": "local3"}})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := n.Validate(); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title: "❌ Command Failed",
+		Body:  "backup.sh\nFailed in 2.0s (exit code 1)",
+		Run:   &RunInfo{Command: `backup.sh "nightly" [x]`, ExitCode: 1, Duration: 2 * time.Second},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	got := read()
+	// local3 (19) * 8 + err (3) = 155
+	pattern := `^<155>1 \S+ \S+ tn \d+ - \[tn@32473 command="backup.sh \\"nightly\\" \[x\\]" exit_code="1" duration_ms="2000"\] ` +
+		"\ufeff❌ Command Failed: backup.sh Failed in 2.0s \\(exit code 1\\)$"
+	if !regexp.MustCompile(pattern).MatchString(got) {
+		t.Errorf("syslog message = %q, want match for %q", got, pattern)
+	}
+}
+
+func TestSyslog_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	n := &syslog{network: "udp", address: conn.LocalAddr().String(), facility: "user"}
+	if err := n.Send(context.Background(), &Message{Title: "hello", Priority: "high"}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 2048)
+	nr, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("reading datagram: %v", err)
+	}
+	got := string(buf[:nr])
+	// user (1) * 8 + warning (4) = 12
+	if !strings.HasPrefix(got, "<12>1 ") || !strings.HasSuffix(got, " - \ufeffhello") {
+		t.Errorf("syslog message = %q, want <12> priority and the title with no structured data", got)
+	}
+}
+
+func TestSyslog_Validate(t *testing.T) {
+	if err := (&syslog{network: "carrier", address: "x", facility: "user"}).Validate(); err == nil {
+		t.Error("Validate() expected error for unknown network, got nil")
+	}
+	if err := (&syslog{network: "udp", facility: "user"}).Validate(); err == nil {
+		t.Error("Validate() expected error for missing address, got nil")
+	}
+	if err := (&syslog{network: "udp", address: "x:514", facility: "local9"}).Validate(); err == nil {
+		t.Error("Validate() expected error for unknown facility, got nil")
+	}
+}
+
+func TestJournald_Send(t *testing.T) {
+	path, read := listenUnixgram(t)
+
+	n, err := New("journald", Options{Params: map[string]string{"address": path}})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title: "✅ Command Succeeded",
+		Body:  "make\nCompleted in 1m 0s",
+		Tags:  "white_check_mark",
+		Run:   &RunInfo{Command: "make", Duration: time.Minute, Cwd: "/src"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	fields := parseJournalEntry(t, read())
+	want := map[string]string{
+		"MESSAGE":           "✅ Command Succeeded\nmake\nCompleted in 1m 0s",
+		"PRIORITY":          "5",
+		"SYSLOG_IDENTIFIER": "tn",
+		"TN_TITLE":          "✅ Command Succeeded",
+		"TN_TAGS":           "white_check_mark",
+		"TN_COMMAND":        "make",
+		"TN_EXIT_CODE":      "0",
+		"TN_DURATION_MS":    "60000",
+		"TN_CWD":            "/src",
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("field %s = %q, want %q", k, fields[k], v)
+		}
+	}
+}
+
+// parseJournalEntry decodes a native-protocol datagram.
+func parseJournalEntry(t *testing.T, data string) map[string]string {
+	t.Helper()
+
+	fields := map[string]string{}
+	for data != "" {
+		nl := strings.IndexByte(data, '\n')
+		if nl < 0 {
+			t.Fatalf("unterminated field in %q", data)
+		}
+		line := data[:nl]
+		data = data[nl+1:]
+
+		if k, v, ok := strings.Cut(line, "="); ok {
+			fields[k] = v
+			continue
+		}
+		size := binary.LittleEndian.Uint64([]byte(data[:8]))
+		fields[line] = data[8 : 8+size]
+		data = data[8+size+1:]
+	}
+	return fields
+}
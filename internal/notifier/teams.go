@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+func init() {
+	Register("teams", func(opts Options) (Notifier, error) {
+		return &teams{webhook: opts.Server}, nil
+	})
+}
+
+// teams posts an Adaptive Card to a Microsoft Teams workflow webhook
+// ("Post to a channel when a webhook request is received").
+type teams struct {
+	webhook string
+}
+
+type teamsPayload struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string        `json:"contentType"`
+	Content     *adaptiveCard `json:"content"`
+}
+
+type adaptiveCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []adaptiveItem `json:"body"`
+	MSTeams map[string]any `json:"msteams,omitempty"`
+}
+
+// adaptiveItem covers the TextBlock and FactSet elements tn uses.
+type adaptiveItem struct {
+	Type     string         `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	Weight   string         `json:"weight,omitempty"`
+	Size     string         `json:"size,omitempty"`
+	Color    string         `json:"color,omitempty"`
+	FontType string         `json:"fontType,omitempty"`
+	Wrap     bool           `json:"wrap,omitempty"`
+	Spacing  string         `json:"spacing,omitempty"`
+	Facts    []adaptiveFact `json:"facts,omitempty"`
+}
+
+type adaptiveFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func (t *teams) Name() string { return "teams" }
+
+// String returns the webhook host; the full URL embeds a signature.
+func (t *teams) String() string {
+	if u, err := url.Parse(t.webhook); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "teams"
+}
+
+func (t *teams) Validate() error {
+	if t.webhook == "" {
+		return fmt.Errorf("teams workflow webhook URL is required — set it as the server")
+	}
+	return nil
+}
+
+func (t *teams) Send(ctx context.Context, msg *Message) error {
+	_, err := doJSON(ctx, "teams", "POST", t.webhook, nil, teamsMessage(msg))
+	return err
+}
+
+// teamsMessage renders msg as an Adaptive Card. Command results get a
+// colored title, a fact set and, if captured, the tail of the output.
+func teamsMessage(msg *Message) *teamsPayload {
+	title := adaptiveItem{Type: "TextBlock", Text: msg.Title, Weight: "Bolder", Size: "Medium", Wrap: true}
+	body := []adaptiveItem{title}
+
+	if run := msg.Run; run != nil {
+		body[0].Color = "Good"
+		if !run.Succeeded() {
+			body[0].Color = "Attention"
+		}
+		facts := []adaptiveFact{
+			{Title: "Command", Value: run.Command},
+			{Title: "Duration", Value: FormatDuration(run.Duration)},
+			{Title: "Exit code", Value: strconv.Itoa(run.ExitCode)},
+		}
+		if run.Host != "" {
+			facts = append(facts, adaptiveFact{Title: "Host", Value: run.Host})
+		}
+		body = append(body, adaptiveItem{Type: "FactSet", Facts: facts})
+		if run.Output != "" {
+			body = append(body, adaptiveItem{
+				Type:     "TextBlock",
+				Text:     run.Output,
+				FontType: "Monospace",
+				Wrap:     true,
+				Spacing:  "Medium",
+			})
+		}
+	} else if msg.Body != "" {
+		body = append(body, adaptiveItem{Type: "TextBlock", Text: msg.Body, Wrap: true})
+	}
+
+	return &teamsPayload{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: &adaptiveCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+				MSTeams: map[string]any{"width": "Full"},
+			},
+		}},
+	}
+}
@@ -0,0 +1,62 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTeams_SendRunResult(t *testing.T) {
+	var payload teamsPayload
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	n, err := New("teams", Options{Server: server.URL + "/workflows/abc/triggers/manual/run"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title: "❌ Command Failed",
+		Run:   &RunInfo{Command: "make test", ExitCode: 2, Duration: 3 * time.Second, Host: "ci-7", Output: "FAIL: TestX\nexit status 2"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if payload.Type != "message" || len(payload.Attachments) != 1 {
+		t.Fatalf("payload = %+v, want one attachment", payload)
+	}
+	att := payload.Attachments[0]
+	if att.ContentType != "application/vnd.microsoft.card.adaptive" || att.Content.Type != "AdaptiveCard" {
+		t.Errorf("attachment = %s / %s, want an adaptive card", att.ContentType, att.Content.Type)
+	}
+
+	body := att.Content.Body
+	if len(body) != 3 {
+		t.Fatalf("len(body) = %d, want title, facts and output", len(body))
+	}
+	if body[0].Color != "Attention" {
+		t.Errorf("title color = %q, want Attention", body[0].Color)
+	}
+	if facts := body[1].Facts; len(facts) != 4 || facts[2].Value != "2" || facts[3].Value != "ci-7" {
+		t.Errorf("facts = %+v", facts)
+	}
+	if body[2].FontType != "Monospace" || body[2].Text != "FAIL: TestX\nexit status 2" {
+		t.Errorf("output block = %+v", body[2])
+	}
+}
+
+func TestTeamsMessage_PlainNotification(t *testing.T) {
+	body := teamsMessage(&Message{Title: "📢 term_notify", Body: "Build complete!"}).Attachments[0].Content.Body
+	if len(body) != 2 || body[1].Text != "Build complete!" || body[0].Color != "" {
+		t.Errorf("body = %+v, want an uncolored title and the message body", body)
+	}
+}
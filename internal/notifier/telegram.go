@@ -0,0 +1,145 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+)
+
+func init() {
+	Register("telegram", func(opts Options) (Notifier, error) {
+		t := &telegram{
+			apiURL:    opts.Params["api_url"],
+			token:     opts.Token,
+			chatID:    opts.Topic,
+			parseMode: opts.Params["parse_mode"],
+		}
+		if t.apiURL == "" {
+			t.apiURL = "https://api.telegram.org"
+		}
+		if t.parseMode == "" {
+			t.parseMode = "HTML"
+		}
+		return t, nil
+	})
+}
+
+// telegram sends messages through the Telegram Bot API. The bot token is
+// taken from Token and the chat ID from Topic.
+type telegram struct {
+	apiURL    string
+	token     string
+	chatID    string
+	parseMode string
+}
+
+func (t *telegram) Name() string { return "telegram" }
+
+// String returns the chat ID; the bot token is a secret.
+func (t *telegram) String() string { return "chat " + t.chatID }
+
+func (t *telegram) Validate() error {
+	if t.token == "" {
+		return fmt.Errorf("telegram bot token is required — run 'tn config --token <token>' or set TN_TOKEN")
+	}
+	if t.chatID == "" {
+		return fmt.Errorf("telegram chat ID is required — run 'tn config --topic <chat-id>' or set TN_TOPIC")
+	}
+	switch t.parseMode {
+	case "HTML", "MarkdownV2", "none":
+		return nil
+	}
+	return fmt.Errorf("unsupported telegram parse_mode %q (use HTML, MarkdownV2 or none)", t.parseMode)
+}
+
+func (t *telegram) Send(ctx context.Context, msg *Message) error {
+	payload := struct {
+		ChatID              string `json:"chat_id"`
+		Text                string `json:"text"`
+		ParseMode           string `json:"parse_mode,omitempty"`
+		DisableNotification bool   `json:"disable_notification,omitempty"`
+	}{
+		ChatID:              t.chatID,
+		Text:                telegramText(msg, t.parseMode),
+		DisableNotification: msg.Priority == "min" || msg.Priority == "low",
+	}
+	if t.parseMode != "none" {
+		payload.ParseMode = t.parseMode
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", baseURL(t.apiURL), t.token)
+	_, err := doJSON(ctx, "telegram", "POST", url, nil, payload)
+	return err
+}
+
+// Telegram rejects messages whose text, once markup is parsed, is longer
+// than telegramMaxText characters. The title is kept short, and the
+// command or body gets what is left after telegramReserve characters for
+// the status line and line breaks.
+const (
+	telegramMaxText  = 4096
+	telegramMaxTitle = 256
+	telegramReserve  = 64
+)
+
+// telegramText renders msg for the given parse mode, escaping user content
+// so characters in the command line can't break the markup. Long content
+// is truncated before it is escaped, so the markup stays intact.
+func telegramText(msg *Message, parseMode string) string {
+	var bold, code, text func(string) string
+
+	switch parseMode {
+	case "HTML":
+		bold = func(s string) string { return "<b>" + html.EscapeString(s) + "</b>" }
+		code = func(s string) string { return "<pre>" + html.EscapeString(s) + "</pre>" }
+		text = html.EscapeString
+	case "MarkdownV2":
+		bold = func(s string) string { return "*" + escapeMarkdownV2(s) + "*" }
+		code = func(s string) string { return "```\n" + escapeMarkdownV2Code(s) + "\n```" }
+		text = escapeMarkdownV2
+	default:
+		plain := func(s string) string { return s }
+		bold, code, text = plain, plain, plain
+	}
+
+	title := truncate(msg.Title, telegramMaxTitle)
+	room := telegramMaxText - telegramReserve - len([]rune(title))
+
+	var lines []string
+	if title != "" {
+		lines = append(lines, bold(title))
+	}
+
+	if run := msg.Run; run != nil {
+		lines = append(lines, code(truncate(run.Command, room)))
+		if run.Succeeded() {
+			lines = append(lines, text("Completed in "+FormatDuration(run.Duration)))
+		} else {
+			lines = append(lines, text(fmt.Sprintf("Failed in %s (exit code %d)", FormatDuration(run.Duration), run.ExitCode)))
+		}
+	} else if msg.Body != "" {
+		lines = append(lines, text(truncate(msg.Body, room)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// escapeMarkdownV2 escapes every character MarkdownV2 reserves outside of
+// code entities.
+func escapeMarkdownV2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("_*[]()~`>#+-=|{}.!\\", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// escapeMarkdownV2Code escapes the characters MarkdownV2 reserves inside
+// pre and code entities.
+func escapeMarkdownV2Code(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(s)
+}
@@ -0,0 +1,116 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestTelegram_Send(t *testing.T) {
+	var capturedPath string
+	var payload map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	n, err := New("telegram", Options{
+		Token:  "123:ABC",
+		Topic:  "-1001234",
+		Params: map[string]string{"api_url": server.URL},
+	})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := n.Validate(); err != nil {
+		t.Fatalf("Validate() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title:    "❌ Command Failed",
+		Priority: "low",
+		Run:      &RunInfo{Command: "grep -q '<x>' a&b", ExitCode: 1, Duration: 3 * time.Second},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if capturedPath != "/bot123:ABC/sendMessage" {
+		t.Errorf("request path = %q, want %q", capturedPath, "/bot123:ABC/sendMessage")
+	}
+	if payload["chat_id"] != "-1001234" {
+		t.Errorf("chat_id = %v, want %q", payload["chat_id"], "-1001234")
+	}
+	if payload["parse_mode"] != "HTML" {
+		t.Errorf("parse_mode = %v, want HTML by default", payload["parse_mode"])
+	}
+	if payload["disable_notification"] != true {
+		t.Errorf("disable_notification = %v, want true for low priority", payload["disable_notification"])
+	}
+	want := "<b>❌ Command Failed</b>\n<pre>grep -q &#39;&lt;x&gt;&#39; a&amp;b</pre>\nFailed in 3.0s (exit code 1)"
+	if payload["text"] != want {
+		t.Errorf("text = %q, want %q", payload["text"], want)
+	}
+}
+
+func TestTelegramText_MarkdownV2(t *testing.T) {
+	msg := &Message{
+		Title: "Build v1.2 done!",
+		Run:   &RunInfo{Command: "echo `date` \\ ok", Duration: time.Second},
+	}
+
+	got := telegramText(msg, "MarkdownV2")
+	want := "*Build v1\\.2 done\\!*\n```\necho \\`date\\` \\\\ ok\n```\nCompleted in 1\\.0s"
+	if got != want {
+		t.Errorf("telegramText() = %q, want %q", got, want)
+	}
+}
+
+func TestTelegramText_PlainBody(t *testing.T) {
+	got := telegramText(&Message{Title: "Note", Body: "a < b"}, "none")
+	if got != "Note\na < b" {
+		t.Errorf("telegramText() = %q, want unescaped title and body", got)
+	}
+}
+
+func TestTelegramText_Limit(t *testing.T) {
+	long := strings.Repeat("<", 5000)
+	msg := &Message{Title: long, Body: long}
+	if n := utf8.RuneCountInString(telegramText(msg, "none")); n > telegramMaxText {
+		t.Errorf("plain text has %d characters, want at most %d", n, telegramMaxText)
+	}
+	got := telegramText(msg, "HTML")
+	if !strings.HasSuffix(got, "&lt;…") {
+		t.Errorf("HTML text ends %q, want the body truncated before escaping", got[len(got)-10:])
+	}
+}
+
+func TestTelegram_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"missing token", Options{Topic: "1"}, true},
+		{"missing chat", Options{Token: "t"}, true},
+		{"bad parse mode", Options{Token: "t", Topic: "1", Params: map[string]string{"parse_mode": "Markdown"}}, true},
+		{"valid", Options{Token: "t", Topic: "1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, _ := New("telegram", tt.opts)
+			if err := n.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
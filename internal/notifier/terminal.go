@@ -0,0 +1,140 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func init() {
+	Register("terminal", func(opts Options) (Notifier, error) {
+		mode := opts.Params["mode"]
+		if mode == "" {
+			mode = "auto"
+		}
+		return &terminal{mode: mode, getenv: os.Getenv}, nil
+	})
+}
+
+// terminal raises a notification in the terminal emulator itself by writing
+// an escape sequence to the controlling terminal. Modes are osc9 (iTerm2,
+// WezTerm, Ghostty), osc777 (foot, urxvt, VTE, WezTerm), osc99 (kitty) and
+// bell; auto picks one from the environment.
+type terminal struct {
+	mode   string
+	getenv func(string) string
+	out    io.Writer // overrides /dev/tty in tests
+}
+
+var terminalModes = map[string]bool{"auto": true, "osc9": true, "osc777": true, "osc99": true, "bell": true}
+
+func (t *terminal) Name() string { return "terminal" }
+
+func (t *terminal) Validate() error {
+	if !terminalModes[t.mode] {
+		return fmt.Errorf("unsupported terminal mode %q (use auto, osc9, osc777, osc99 or bell)", t.mode)
+	}
+	return nil
+}
+
+func (t *terminal) Send(ctx context.Context, msg *Message) error {
+	mode := t.mode
+	if mode == "auto" {
+		mode = detectTerminalMode(t.getenv)
+	}
+	seq := wrapPassthrough(terminalSequence(mode, msg.Title, msg.Body), t.getenv)
+
+	out := t.out
+	if out == nil {
+		// Prefer the controlling terminal so the sequence still reaches the
+		// emulator when stdout and stderr are redirected.
+		if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+			defer tty.Close() //nolint:errcheck
+			out = tty
+		} else {
+			out = os.Stderr
+		}
+	}
+
+	if _, err := io.WriteString(out, seq); err != nil {
+		return fmt.Errorf("writing to terminal: %w", err)
+	}
+	return nil
+}
+
+// detectTerminalMode guesses the notification sequence the surrounding
+// terminal understands, falling back to a plain bell.
+func detectTerminalMode(getenv func(string) string) string {
+	program := getenv("TERM_PROGRAM")
+	if program == "tmux" || program == "screen" {
+		// Multiplexers overwrite TERM_PROGRAM; iTerm2 and WezTerm also
+		// export LC_TERMINAL, which survives into the session.
+		program = getenv("LC_TERMINAL")
+	}
+	term := getenv("TERM")
+
+	switch {
+	case program == "iTerm.app" || program == "iTerm2" || program == "ghostty":
+		return "osc9"
+	case program == "WezTerm":
+		return "osc777"
+	case term == "xterm-kitty" || getenv("KITTY_WINDOW_ID") != "":
+		return "osc99"
+	case strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "rxvt") || getenv("VTE_VERSION") != "":
+		return "osc777"
+	}
+	return "bell"
+}
+
+// terminalSequence builds the escape sequence for mode.
+func terminalSequence(mode, title, body string) string {
+	title, body = sanitizeTerminal(title), sanitizeTerminal(body)
+
+	switch mode {
+	case "osc9":
+		text := title
+		if body != "" {
+			text = strings.TrimPrefix(title+": "+body, ": ")
+		}
+		return "\x1b]9;" + text + "\x07"
+	case "osc777":
+		return "\x1b]777;notify;" + strings.ReplaceAll(title, ";", ",") + ";" + body + "\x07"
+	case "osc99":
+		// Send the title and body as two chunks of one notification; d=0
+		// marks the first as incomplete.
+		return "\x1b]99;i=tn:d=0:p=title;" + title + "\x1b\\" +
+			"\x1b]99;i=tn:d=1:p=body;" + body + "\x1b\\"
+	}
+	return "\a"
+}
+
+// wrapPassthrough wraps seq so tmux or screen forward it to the outer
+// terminal rather than swallowing it. A bare bell needs no wrapping.
+func wrapPassthrough(seq string, getenv func(string) string) string {
+	if seq == "\a" {
+		return seq
+	}
+	switch {
+	case getenv("TMUX") != "":
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case getenv("STY") != "":
+		return "\x1bP" + seq + "\x1b\\"
+	}
+	return seq
+}
+
+// sanitizeTerminal drops control characters that could terminate the
+// sequence early and folds newlines into spaces.
+func sanitizeTerminal(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\t':
+			return ' '
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+}
@@ -0,0 +1,102 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func envFunc(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+func TestDetectTerminalMode(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"iTerm2", map[string]string{"TERM_PROGRAM": "iTerm.app"}, "osc9"},
+		{"iTerm2 inside tmux", map[string]string{"TERM_PROGRAM": "tmux", "LC_TERMINAL": "iTerm2"}, "osc9"},
+		{"WezTerm", map[string]string{"TERM_PROGRAM": "WezTerm"}, "osc777"},
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, "osc99"},
+		{"kitty inside tmux", map[string]string{"TERM": "tmux-256color", "KITTY_WINDOW_ID": "1"}, "osc99"},
+		{"foot", map[string]string{"TERM": "foot"}, "osc777"},
+		{"VTE", map[string]string{"TERM": "xterm-256color", "VTE_VERSION": "7600"}, "osc777"},
+		{"unknown", map[string]string{"TERM": "xterm"}, "bell"},
+		{"empty", map[string]string{}, "bell"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectTerminalMode(envFunc(tt.env)); got != tt.want {
+				t.Errorf("detectTerminalMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerminalSequence(t *testing.T) {
+	tests := []struct {
+		mode, title, body, want string
+	}{
+		{"osc9", "Done", "make ok", "\x1b]9;Done: make ok\x07"},
+		{"osc9", "Done", "", "\x1b]9;Done\x07"},
+		{"osc777", "a;b", "line1\nline2", "\x1b]777;notify;a,b;line1 line2\x07"},
+		{"osc99", "T", "B", "\x1b]99;i=tn:d=0:p=title;T\x1b\\\x1b]99;i=tn:d=1:p=body;B\x1b\\"},
+		{"bell", "T", "B", "\a"},
+		{"osc9", "evil\x1b]0;x\x07", "", "\x1b]9;evil]0;x\x07"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			if got := terminalSequence(tt.mode, tt.title, tt.body); got != tt.want {
+				t.Errorf("terminalSequence(%q) = %q, want %q", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapPassthrough(t *testing.T) {
+	seq := "\x1b]9;hi\x07"
+
+	if got := wrapPassthrough(seq, envFunc(nil)); got != seq {
+		t.Errorf("no multiplexer: got %q, want unchanged", got)
+	}
+	if got := wrapPassthrough(seq, envFunc(map[string]string{"TMUX": "/tmp/tmux-0/default,1,0"})); got != "\x1bPtmux;\x1b\x1b]9;hi\x07\x1b\\" {
+		t.Errorf("tmux: got %q", got)
+	}
+	if got := wrapPassthrough(seq, envFunc(map[string]string{"STY": "123.pts-0"})); got != "\x1bP\x1b]9;hi\x07\x1b\\" {
+		t.Errorf("screen: got %q", got)
+	}
+	if got := wrapPassthrough("\a", envFunc(map[string]string{"TMUX": "x"})); got != "\a" {
+		t.Errorf("bell under tmux: got %q, want bare bell", got)
+	}
+}
+
+func TestTerminal_Send(t *testing.T) {
+	var out bytes.Buffer
+	n := &terminal{
+		mode:   "auto",
+		getenv: envFunc(map[string]string{"TERM_PROGRAM": "iTerm.app"}),
+		out:    &out,
+	}
+
+	if err := n.Send(context.Background(), &Message{Title: "✅ Command Succeeded", Body: "make"}); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+	if got := out.String(); got != "\x1b]9;✅ Command Succeeded: make\x07" {
+		t.Errorf("wrote %q", got)
+	}
+}
+
+func TestTerminal_Validate(t *testing.T) {
+	n, _ := New("terminal", Options{})
+	if err := n.Validate(); err != nil {
+		t.Errorf("Validate() returned unexpected error for default mode: %v", err)
+	}
+	n, _ = New("terminal", Options{Params: map[string]string{"mode": "osc1337"}})
+	if err := n.Validate(); err == nil {
+		t.Error("Validate() expected error for unknown mode, got nil")
+	}
+}
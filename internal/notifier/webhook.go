@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+func init() {
+	Register("webhook", newWebhook)
+}
+
+// webhook sends an HTTP request whose URL, headers and body are Go
+// text/templates rendered against the message. The URL template comes from
+// Server, the body and method from params, and headers from Headers.
+type webhook struct {
+	rawURL  string
+	method  string
+	url     *template.Template
+	body    *template.Template // nil sends the template data as JSON
+	headers map[string]*template.Template
+}
+
+// webhookFuncs are available to every webhook template in addition to the
+// text/template builtins.
+var webhookFuncs = template.FuncMap{
+	// json encodes a value for embedding in a JSON body, e.g. {{json .Title}}.
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func newWebhook(opts Options) (Notifier, error) {
+	w := &webhook{
+		rawURL:  opts.Server,
+		method:  strings.ToUpper(opts.Params["method"]),
+		headers: map[string]*template.Template{},
+	}
+	if w.method == "" {
+		w.method = "POST"
+	}
+
+	var err error
+	if w.url, err = template.New("url").Funcs(webhookFuncs).Parse(opts.Server); err != nil {
+		return nil, fmt.Errorf("parsing webhook URL template: %w", err)
+	}
+	if body := opts.Params["body"]; body != "" {
+		if w.body, err = template.New("body").Funcs(webhookFuncs).Parse(body); err != nil {
+			return nil, fmt.Errorf("parsing webhook body template: %w", err)
+		}
+	}
+	for name, value := range opts.Headers {
+		if w.headers[name], err = template.New(name).Funcs(webhookFuncs).Parse(value); err != nil {
+			return nil, fmt.Errorf("parsing webhook header %s template: %w", name, err)
+		}
+	}
+
+	return w, nil
+}
+
+func (w *webhook) Name() string { return "webhook" }
+
+// String returns the URL host, for status messages.
+func (w *webhook) String() string {
+	if u, err := url.Parse(w.rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "webhook"
+}
+
+func (w *webhook) Validate() error {
+	if w.rawURL == "" {
+		return fmt.Errorf("webhook URL is required — set it as the server")
+	}
+	return nil
+}
+
+func (w *webhook) Send(ctx context.Context, msg *Message) error {
+	data := newRunEvent(msg)
+
+	target, err := render(w.url, data)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if w.body != nil {
+		rendered, err := render(w.body, data)
+		if err != nil {
+			return err
+		}
+		body = []byte(rendered)
+	} else if body, err = json.Marshal(data); err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, w.method, strings.TrimSpace(target), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for name, tmpl := range w.headers {
+		value, err := render(tmpl, data)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(name, value)
+	}
+
+	_, err = do("webhook", req)
+	return err
+}
+
+func render(tmpl *template.Template, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering webhook %s template: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Templates(t *testing.T) {
+	var capturedReq *http.Request
+	var capturedBody string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedReq = r.Clone(r.Context())
+		body, _ := io.ReadAll(r.Body)
+		capturedBody = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	n, err := New("webhook", Options{
+		Server: server.URL + "/jobs/{{.Host}}/{{if .Success}}ok{{else}}fail{{end}}?code={{.ExitCode}}",
+		Params: map[string]string{
+			"method": "put",
+			"body":   `{"text":{{json .Title}},"cmd":{{json .Command}},"ms":{{.DurationMS}},"tags":{{json .Tags}}}`,
+		},
+		Headers: map[string]string{
+			"X-Exit-Code":   "{{.ExitCode}}",
+			"Authorization": "Bearer static-token",
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title: `❌ "quoted" title`,
+		Tags:  "x, ci",
+		Run:   &RunInfo{Command: "make test", ExitCode: 3, Duration: 1500 * time.Millisecond, Host: "box", Cwd: "/src"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if capturedReq.Method != "PUT" {
+		t.Errorf("method = %q, want PUT", capturedReq.Method)
+	}
+	if capturedReq.URL.Path != "/jobs/box/fail" || capturedReq.URL.Query().Get("code") != "3" {
+		t.Errorf("URL = %q, want rendered path and query", capturedReq.URL.String())
+	}
+	if got := capturedReq.Header.Get("X-Exit-Code"); got != "3" {
+		t.Errorf("X-Exit-Code header = %q, want %q", got, "3")
+	}
+	if got := capturedReq.Header.Get("Authorization"); got != "Bearer static-token" {
+		t.Errorf("Authorization header = %q, want %q", got, "Bearer static-token")
+	}
+	want := `{"text":"❌ \"quoted\" title","cmd":"make test","ms":1500,"tags":["x","ci"]}`
+	if capturedBody != want {
+		t.Errorf("body = %s, want %s", capturedBody, want)
+	}
+}
+
+func TestWebhook_DefaultJSONBody(t *testing.T) {
+	var data runEvent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("method = %q, want POST by default", r.Method)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&data)
+	}))
+	defer server.Close()
+
+	n, err := New("webhook", Options{Server: server.URL})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+
+	msg := &Message{
+		Title: "done",
+		Run:   &RunInfo{Command: "sleep 1", Duration: time.Second, Host: "box", Cwd: "/tmp"},
+	}
+	if err := n.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() returned unexpected error: %v", err)
+	}
+
+	if data.Title != "done" || data.Command != "sleep 1" || !data.Success {
+		t.Errorf("payload = %+v, want title, command and success", data)
+	}
+	if data.DurationMS != 1000 || data.Host != "box" || data.Cwd != "/tmp" {
+		t.Errorf("payload = %+v, want run details", data)
+	}
+}
+
+func TestWebhook_BadTemplate(t *testing.T) {
+	_, err := New("webhook", Options{
+		Server: "https://example.com",
+		Params: map[string]string{"body": "{{.Title"},
+	})
+	if err == nil {
+		t.Fatal("New() expected error for malformed body template, got nil")
+	}
+}
+
+func TestWebhook_UnknownField(t *testing.T) {
+	n, err := New("webhook", Options{Server: "https://example.com/{{.Nope}}"})
+	if err != nil {
+		t.Fatalf("New() returned unexpected error: %v", err)
+	}
+	if err := n.Send(context.Background(), &Message{}); err == nil {
+		t.Fatal("Send() expected error for unknown template field, got nil")
+	}
+}
@@ -0,0 +1,297 @@
+// Package outbox keeps notifications that could not be delivered on disk,
+// so they can be sent once the network is back.
+//
+// Each queued notification is one JSON file holding the message and the
+// destination it failed to reach. A file is claimed by renaming it before
+// it is resent, so two tn processes never deliver the same entry twice.
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lee/term_notify/internal/config"
+	"github.com/lee/term_notify/internal/notifier"
+)
+
+const (
+	entryExt = ".json"
+	claimExt = ".sending"
+	// staleClaim is how long a claimed entry may go unfinished before it
+	// is assumed to belong to a tn process that died, and is listed again.
+	staleClaim = time.Hour
+)
+
+// Entry is a notification waiting to be resent.
+type Entry struct {
+	// ID names the entry's files. IDs sort in the order entries were queued.
+	ID string `json:"-"`
+
+	Queued time.Time `json:"queued"`
+	// Due is when a scheduled message should arrive; Message.Delay is
+	// recomputed from it when the entry is resent.
+	Due      time.Time `json:"due,omitzero"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error"`
+
+	Destination config.Destination `json:"destination"`
+	Message     notifier.Message   `json:"message"`
+
+	claimed bool
+}
+
+// Outbox is a directory of queued notifications.
+type Outbox struct {
+	dir string
+}
+
+// New returns the outbox stored in dir. The directory is created when the
+// first entry is added.
+func New(dir string) *Outbox {
+	return &Outbox{dir: dir}
+}
+
+// Open returns the outbox in the config directory.
+func Open() (*Outbox, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return New(filepath.Join(dir, "outbox")), nil
+}
+
+// Dir returns the directory the outbox is stored in.
+func (o *Outbox) Dir() string { return o.dir }
+
+// Add queues msg for dest. A scheduled message keeps its due time, and an
+// attached file is copied into the outbox, since the original is usually
+// temporary.
+func (o *Outbox) Add(dest config.Destination, msg *notifier.Message, attempts int, sendErr error) (*Entry, error) {
+	if err := os.MkdirAll(o.dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating outbox: %w", err)
+	}
+
+	now := time.Now()
+	e := &Entry{
+		ID:          fmt.Sprintf("%d-%04x", now.UnixNano(), rand.N(1<<16)), // #nosec G404 — only needs to be unique
+		Queued:      now,
+		Attempts:    attempts,
+		Destination: dest,
+		Message:     *msg,
+	}
+	if sendErr != nil {
+		e.Error = sendErr.Error()
+	}
+	if msg.Delay > 0 {
+		e.Due = now.Add(msg.Delay)
+		e.Message.Delay = 0
+	}
+
+	if a := msg.Attachment; a != nil {
+		path := filepath.Join(o.dir, e.ID+filepath.Ext(a.Name))
+		if err := copyFile(a.Path, path); err != nil {
+			return nil, fmt.Errorf("copying attachment: %w", err)
+		}
+		e.Message.Attachment = &notifier.Attachment{Name: a.Name, Path: path}
+	}
+
+	if err := o.write(e, e.ID+entryExt); err != nil {
+		o.removeAttachment(e)
+		return nil, err
+	}
+	return e, nil
+}
+
+// List returns the queued entries, oldest first. Entries being resent by
+// another tn process are left out.
+func (o *Outbox) List() ([]*Entry, error) {
+	files, err := os.ReadDir(o.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading outbox: %w", err)
+	}
+
+	var entries []*Entry
+	for _, f := range files {
+		name := f.Name()
+		id, claimed := strings.CutSuffix(name, claimExt)
+		if !claimed {
+			var ok bool
+			if id, ok = strings.CutSuffix(name, entryExt); !ok {
+				continue
+			}
+		}
+		if claimed {
+			info, err := f.Info()
+			if err != nil || time.Since(info.ModTime()) < staleClaim {
+				continue
+			}
+		}
+
+		e, err := o.read(name)
+		if errors.Is(err, os.ErrNotExist) {
+			// Claimed by another process since the directory was read.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.ID, e.claimed = id, claimed
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, nil
+}
+
+// Claim marks e as being resent. It returns false if another process got
+// to it first.
+func (o *Outbox) Claim(e *Entry) bool {
+	if e.claimed {
+		// A stale claim; take it over.
+		now := time.Now()
+		return os.Chtimes(o.path(e), now, now) == nil
+	}
+	if os.Rename(filepath.Join(o.dir, e.ID+entryExt), filepath.Join(o.dir, e.ID+claimExt)) != nil {
+		return false
+	}
+	e.claimed = true
+	return true
+}
+
+// Release returns a claimed entry to the queue, saving its updated
+// attempt count and error.
+func (o *Outbox) Release(e *Entry) error {
+	if err := o.write(e, e.ID+entryExt); err != nil {
+		return err
+	}
+	if e.claimed {
+		e.claimed = false
+		if err := os.Remove(filepath.Join(o.dir, e.ID+claimExt)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("releasing outbox entry: %w", err)
+		}
+	}
+	return nil
+}
+
+// Remove deletes e and its attachment from the outbox.
+func (o *Outbox) Remove(e *Entry) error {
+	o.removeAttachment(e)
+	if err := os.Remove(o.path(e)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("removing outbox entry: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes the outbox, including entries that cannot be read, and
+// returns how many entries there were.
+func (o *Outbox) Purge() (int, error) {
+	files, err := os.ReadDir(o.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading outbox: %w", err)
+	}
+
+	n := 0
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), entryExt) || strings.HasSuffix(f.Name(), claimExt) {
+			n++
+		}
+	}
+	if err := os.RemoveAll(o.dir); err != nil {
+		return 0, fmt.Errorf("purging outbox: %w", err)
+	}
+	return n, nil
+}
+
+// ResendMessage returns the entry's message ready to resend, with the
+// delay of a scheduled message measured from now.
+func (e *Entry) ResendMessage() *notifier.Message {
+	m := e.Message
+	if !e.Due.IsZero() {
+		m.Delay = max(time.Until(e.Due), 0)
+	}
+	return &m
+}
+
+func (o *Outbox) path(e *Entry) string {
+	if e.claimed {
+		return filepath.Join(o.dir, e.ID+claimExt)
+	}
+	return filepath.Join(o.dir, e.ID+entryExt)
+}
+
+// write saves e under name, replacing any existing file atomically.
+func (o *Outbox) write(e *Entry, name string) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding outbox entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(o.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("writing outbox entry: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck
+		return fmt.Errorf("writing outbox entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing outbox entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(o.dir, name)); err != nil {
+		return fmt.Errorf("writing outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (o *Outbox) read(name string) (*Entry, error) {
+	data, err := os.ReadFile(filepath.Join(o.dir, name)) // #nosec G304 — file is in the outbox
+	if err != nil {
+		return nil, fmt.Errorf("reading outbox entry: %w", err)
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("reading outbox entry %s: %w", name, err)
+	}
+	return &e, nil
+}
+
+func (o *Outbox) removeAttachment(e *Entry) {
+	if a := e.Message.Attachment; a != nil && filepath.Dir(a.Path) == o.dir {
+		_ = os.Remove(a.Path)
+	}
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) // #nosec G304 — attachment path is chosen by tn
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) // #nosec G304
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()    //nolint:errcheck
+		os.Remove(dst) //nolint:errcheck
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,183 @@
+package outbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lee/term_notify/internal/config"
+	"github.com/lee/term_notify/internal/notifier"
+)
+
+func TestOutbox_AddAndList(t *testing.T) {
+	ob := New(filepath.Join(t.TempDir(), "outbox"))
+
+	dest := config.Destination{Name: "phone", Backend: "ntfy", Topic: "builds", Token: "tk_x"}
+	first, err := ob.Add(dest, &notifier.Message{Title: "first", Run: &notifier.RunInfo{Command: "make", ExitCode: 2}}, 4, errors.New("network is unreachable"))
+	if err != nil {
+		t.Fatalf("Add() returned unexpected error: %v", err)
+	}
+	if _, err := ob.Add(dest, &notifier.Message{Title: "second"}, 1, nil); err != nil {
+		t.Fatalf("Add() returned unexpected error: %v", err)
+	}
+
+	entries, err := ob.List()
+	if err != nil {
+		t.Fatalf("List() returned unexpected error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Message.Title != "first" || entries[1].Message.Title != "second" {
+		t.Fatalf("List() = %+v, want first and second in order", entries)
+	}
+
+	e := entries[0]
+	if e.ID != first.ID || e.Attempts != 4 || e.Error != "network is unreachable" {
+		t.Errorf("entry = %+v, want the attempts and error it was queued with", e)
+	}
+	if e.Destination.Token != "tk_x" || e.Message.Run == nil || e.Message.Run.ExitCode != 2 {
+		t.Errorf("entry = %+v, want destination and run info round-tripped", e)
+	}
+
+	info, err := os.Stat(ob.Dir())
+	if err != nil {
+		t.Fatalf("stat outbox: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		t.Errorf("outbox mode = %o, want it private", perm)
+	}
+}
+
+func TestOutbox_ListMissingDir(t *testing.T) {
+	entries, err := New(filepath.Join(t.TempDir(), "outbox")).List()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("List() = %v, %v; want nothing", entries, err)
+	}
+}
+
+func TestOutbox_ClaimReleaseRemove(t *testing.T) {
+	ob := New(t.TempDir())
+	if _, err := ob.Add(config.Destination{Name: "phone"}, &notifier.Message{Title: "t"}, 1, nil); err != nil {
+		t.Fatalf("Add() returned unexpected error: %v", err)
+	}
+
+	entries, _ := ob.List()
+	other, _ := ob.List()
+	e := entries[0]
+	if !ob.Claim(e) {
+		t.Fatal("Claim() = false, want true")
+	}
+	if ob.Claim(other[0]) {
+		t.Error("second Claim() = true, want the entry to be taken")
+	}
+	if claimed, _ := ob.List(); len(claimed) != 0 {
+		t.Errorf("List() while claimed = %d entries, want 0", len(claimed))
+	}
+
+	e.Attempts, e.Error = 2, "timeout"
+	if err := ob.Release(e); err != nil {
+		t.Fatalf("Release() returned unexpected error: %v", err)
+	}
+	entries, _ = ob.List()
+	if len(entries) != 1 || entries[0].Attempts != 2 || entries[0].Error != "timeout" {
+		t.Fatalf("List() after Release() = %+v, want the updated entry", entries)
+	}
+
+	if !ob.Claim(entries[0]) {
+		t.Fatal("Claim() after Release() = false, want true")
+	}
+	if err := ob.Remove(entries[0]); err != nil {
+		t.Fatalf("Remove() returned unexpected error: %v", err)
+	}
+	files, _ := os.ReadDir(ob.Dir())
+	if len(files) != 0 {
+		t.Errorf("outbox has %d files after Remove(), want 0", len(files))
+	}
+}
+
+func TestOutbox_StaleClaim(t *testing.T) {
+	ob := New(t.TempDir())
+	if _, err := ob.Add(config.Destination{Name: "phone"}, &notifier.Message{Title: "t"}, 1, nil); err != nil {
+		t.Fatalf("Add() returned unexpected error: %v", err)
+	}
+	entries, _ := ob.List()
+	if !ob.Claim(entries[0]) {
+		t.Fatal("Claim() = false, want true")
+	}
+
+	old := time.Now().Add(-2 * staleClaim)
+	if err := os.Chtimes(ob.path(entries[0]), old, old); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ = ob.List()
+	if len(entries) != 1 || !ob.Claim(entries[0]) {
+		t.Errorf("stale claim was not listed and reclaimed: %+v", entries)
+	}
+}
+
+func TestOutbox_Attachment(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "tn-123.log")
+	if err := os.WriteFile(src, []byte("boom\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ob := New(filepath.Join(t.TempDir(), "outbox"))
+	e, err := ob.Add(config.Destination{Name: "phone"}, &notifier.Message{
+		Attachment: &notifier.Attachment{Name: "make.log", Path: src},
+	}, 1, nil)
+	if err != nil {
+		t.Fatalf("Add() returned unexpected error: %v", err)
+	}
+
+	// The original is a temp file that tn run deletes.
+	if err := os.Remove(src); err != nil {
+		t.Fatal(err)
+	}
+	a := e.Message.Attachment
+	if data, err := os.ReadFile(a.Path); err != nil || string(data) != "boom\n" || a.Name != "make.log" {
+		t.Errorf("attachment = %+v (%q, %v), want a copy in the outbox", a, data, err)
+	}
+
+	if err := ob.Remove(e); err != nil {
+		t.Fatalf("Remove() returned unexpected error: %v", err)
+	}
+	if _, err := os.Stat(a.Path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("attachment copy still exists after Remove(): %v", err)
+	}
+}
+
+func TestOutbox_Schedule(t *testing.T) {
+	ob := New(t.TempDir())
+	e, err := ob.Add(config.Destination{Name: "phone"}, &notifier.Message{Delay: time.Hour}, 1, nil)
+	if err != nil {
+		t.Fatalf("Add() returned unexpected error: %v", err)
+	}
+	if d := e.ResendMessage().Delay; d <= 59*time.Minute || d > time.Hour {
+		t.Errorf("ResendMessage().Delay = %s, want just under 1h", d)
+	}
+
+	e.Due = time.Now().Add(-time.Minute)
+	if d := e.ResendMessage().Delay; d != 0 {
+		t.Errorf("ResendMessage().Delay for an overdue message = %s, want 0", d)
+	}
+}
+
+func TestOutbox_Purge(t *testing.T) {
+	ob := New(filepath.Join(t.TempDir(), "outbox"))
+	for range 3 {
+		if _, err := ob.Add(config.Destination{Name: "phone"}, &notifier.Message{}, 1, nil); err != nil {
+			t.Fatalf("Add() returned unexpected error: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(ob.Dir(), "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := ob.Purge()
+	if err != nil || n != 4 {
+		t.Errorf("Purge() = %d, %v; want 4", n, err)
+	}
+	if entries, err := ob.List(); err != nil || len(entries) != 0 {
+		t.Errorf("List() after Purge() = %v, %v; want nothing", entries, err)
+	}
+}